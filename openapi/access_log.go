@@ -0,0 +1,149 @@
+package openapi
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// accessLogEntry is a request read from an access log
+type accessLogEntry struct {
+	Method string
+	// Path is the path of the request, which may include a query string
+	Path   string
+	Status int
+}
+
+// commonLogPattern matches lines in the Common and Combined Log Formats, e.g.
+// 127.0.0.1 - - [10/Oct/2000:13:55:36 -0700] "GET /pets/42 HTTP/1.1" 200 2326
+var commonLogPattern = regexp.MustCompile(`^\S+ \S+ .*?\[[^\]]+\] "(\S+) (\S+)(?: [^"]*)?" (\d{3})(?:\s|$)`)
+
+// Field names used for the method, path and status of JSON lines logs, in
+// order of preference
+var (
+	jsonLogMethodFields = []string{"method", "request_method", "http_method", "verb"}
+	jsonLogPathFields   = []string{"path", "request_uri", "uri", "url"}
+	jsonLogStatusFields = []string{"status", "status_code", "response_status"}
+)
+
+// maxAccessLogLine is the longest line read from an access log. Longer lines
+// are skipped rather than failing the whole log.
+const maxAccessLogLine = 1024 * 1024
+
+// readAccessLog calls fn for each request in the access log at path. Lines
+// that are neither in Common or Combined Log Format nor JSON objects with a
+// method, path and status are skipped, as are lines longer than
+// maxAccessLogLine, and their number returned.
+func readAccessLog(path string, fn func(e accessLogEntry)) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	skipped := 0
+	reader := bufio.NewReaderSize(f, 64*1024)
+	var buf []byte
+	tooLong := false
+	for {
+		chunk, isPrefix, err := reader.ReadLine()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return skipped, err
+		}
+
+		// Long lines are returned in chunks, keep reading until the end of
+		// the line even once it is known to be skipped
+		if !tooLong {
+			if len(buf)+len(chunk) > maxAccessLogLine {
+				tooLong = true
+				buf = buf[:0]
+			} else {
+				buf = append(buf, chunk...)
+			}
+		}
+		if isPrefix {
+			continue
+		}
+		if tooLong {
+			skipped++
+			tooLong = false
+			continue
+		}
+
+		line := strings.TrimSpace(string(buf))
+		buf = buf[:0]
+		if line == "" {
+			continue
+		}
+		e, ok := parseAccessLogLine(line)
+		if !ok {
+			skipped++
+			continue
+		}
+		fn(e)
+	}
+	return skipped, nil
+}
+
+func parseAccessLogLine(line string) (accessLogEntry, bool) {
+	if strings.HasPrefix(line, "{") {
+		return parseJSONLogLine(line)
+	}
+	m := commonLogPattern.FindStringSubmatch(line)
+	if m == nil {
+		return accessLogEntry{}, false
+	}
+	status, _ := strconv.Atoi(m[3])
+	return accessLogEntry{Method: strings.ToUpper(m[1]), Path: m[2], Status: status}, true
+}
+
+func parseJSONLogLine(line string) (accessLogEntry, bool) {
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(line), &fields); err != nil {
+		return accessLogEntry{}, false
+	}
+	e := accessLogEntry{
+		Method: strings.ToUpper(jsonLogField(fields, jsonLogMethodFields)),
+		Path:   jsonLogField(fields, jsonLogPathFields),
+	}
+	e.Status, _ = strconv.Atoi(jsonLogField(fields, jsonLogStatusFields))
+
+	// Full URLs are matched by their path
+	if i := strings.Index(e.Path, "://"); i >= 0 {
+		rest := e.Path[i+3:]
+		if j := strings.Index(rest, "/"); j >= 0 {
+			e.Path = rest[j:]
+		} else {
+			e.Path = "/"
+		}
+	}
+	if e.Method == "" || e.Path == "" || e.Status == 0 {
+		return accessLogEntry{}, false
+	}
+	return e, true
+}
+
+// jsonLogField returns the first of names found in fields, as a string
+func jsonLogField(fields map[string]interface{}, names []string) string {
+	for _, name := range names {
+		switch v := fields[name].(type) {
+		case string:
+			return v
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64)
+		case nil:
+			continue
+		default:
+			return fmt.Sprint(v)
+		}
+	}
+	return ""
+}
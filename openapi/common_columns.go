@@ -0,0 +1,28 @@
+package openapi
+
+import (
+	"context"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+// commonColumns adds the columns shared by all tables, which describe where
+// the definition of each row was loaded from. Row types must have a Path field.
+func commonColumns(c []*plugin.Column) []*plugin.Column {
+	return append(c, []*plugin.Column{
+		{Name: "labels", Description: "The labels of the definition, from the attributes of the paths blocks matching it.", Type: proto.ColumnType_JSON, Hydrate: getPathLabels, Transform: transform.FromValue()},
+		{Name: "source_url", Description: "The URL the definition was fetched from, if it was loaded over HTTP.", Type: proto.ColumnType_STRING, Transform: transform.FromField("Path").Transform(sourceURLFromPath)},
+	}...)
+}
+
+//// TRANSFORM FUNCTIONS
+
+func sourceURLFromPath(_ context.Context, d *transform.TransformData) (interface{}, error) {
+	path, ok := d.Value.(string)
+	if !ok || !isHTTPURL(path) {
+		return nil, nil
+	}
+	return path, nil
+}
@@ -1,11 +1,40 @@
 package openapi
 
 import (
+	"github.com/hashicorp/hcl/v2"
 	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
 )
 
 type openAPIConfig struct {
-	Paths []string `hcl:"paths,optional" steampipe:"watch"`
+	Paths                 []string            `hcl:"paths,optional"`
+	PathBlocks            []pathsBlock        `hcl:"paths,block"`
+	ExcludePaths          []string            `hcl:"exclude_paths,optional"`
+	SniffContent          *bool               `hcl:"sniff_content,optional"`
+	AllowExternalRefs     *bool               `hcl:"allow_external_refs,optional"`
+	InlineSpecs           map[string]string   `hcl:"inline_specs,optional"`
+	Overlays              map[string][]string `hcl:"overlays,optional"`
+	URLs                  []string            `hcl:"urls,optional"`
+	URLHeaders            map[string]string   `hcl:"url_headers,optional"`
+	URLBearerToken        *string             `hcl:"url_bearer_token,optional"`
+	URLTimeout            *int                `hcl:"url_timeout,optional"`
+	URLInsecureSkipVerify *bool               `hcl:"url_insecure_skip_verify,optional"`
+	URLCACertFile         *string             `hcl:"url_ca_cert_file,optional"`
+	CacheDir              *string             `hcl:"cache_dir,optional"`
+	MaxConcurrentLoads    *int                `hcl:"max_concurrent_loads,optional"`
+	MaxLoadMemoryMB       *int                `hcl:"max_load_memory_mb,optional"`
+	HarPaths              []string            `hcl:"har_paths,optional"`
+	AccessLogPaths        []string            `hcl:"access_log_paths,optional"`
+}
+
+// pathsBlock is an entry of paths given as a block, with the labels of the
+// definitions it matches as attributes, e.g.
+//
+//	paths "~/src/payments/**/*.yaml" {
+//	  team = "payments"
+//	}
+type pathsBlock struct {
+	Pattern string         `hcl:"pattern,label"`
+	Labels  hcl.Attributes `hcl:",remain"`
 }
 
 func ConfigInstance() interface{} {
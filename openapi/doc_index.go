@@ -0,0 +1,519 @@
+package openapi
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/turbot/steampipe-plugin-sdk/v5/memoize"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+// docIndex is a flattened view of a parsed document, built once per path per
+// connection and shared by all tables. Every entry carries the JSON pointer
+// of the object it describes in the root document.
+type docIndex struct {
+	Doc        *openapi3.T
+	Operations []*indexedOperation
+	Parameters []*indexedParameter
+	Responses  []*indexedResponse
+	Schemas    []*indexedSchema
+	Components []*indexedComponent
+	Examples   []*indexedExample
+	Refs       []*indexedRef
+	// MappingRefs are references made other than by $ref, from the values
+	// of discriminator mappings and the operationRef of links
+	MappingRefs []*indexedRef
+	Extensions  []*indexedExtension
+}
+
+// indexedOperation is an operation of a path item, e.g. GET /pets
+type indexedOperation struct {
+	Pointer   string
+	ApiPath   string
+	Method    string
+	PathItem  *openapi3.PathItem
+	Operation *openapi3.Operation
+}
+
+// indexedParameter is a parameter declared on a path item or an operation.
+// Method is empty for parameters declared on the path item.
+type indexedParameter struct {
+	Pointer   string
+	ApiPath   string
+	Method    string
+	Parameter *openapi3.ParameterRef
+}
+
+// indexedResponse is a response declared on an operation
+type indexedResponse struct {
+	Pointer  string
+	ApiPath  string
+	Method   string
+	Status   string
+	Response *openapi3.ResponseRef
+}
+
+// indexedSchema is a schema declared in components
+type indexedSchema struct {
+	Pointer string
+	Name    string
+	Schema  *openapi3.SchemaRef
+}
+
+// indexedComponent is an object declared in components. Section is the key
+// of the map it is declared in, e.g. parameters, and Ref the object declared,
+// e.g. an *openapi3.ParameterRef.
+type indexedComponent struct {
+	Pointer string
+	Section string
+	Name    string
+	Ref     interface{}
+}
+
+// indexedExample is an example found in the document. Location is where it
+// is declared: component, media_type, parameter, header or schema. Name is
+// the key of the example in an examples map, or empty for an example field,
+// in which case Example is nil. Schema is the schema the example is for, if
+// any. Context is request for an example within a request body, response for
+// one within a response, or empty otherwise.
+type indexedExample struct {
+	Pointer  string
+	Location string
+	Context  string
+	Name     string
+	Example  *openapi3.ExampleRef
+	Value    interface{}
+	Schema   *openapi3.SchemaRef
+}
+
+// indexedRef is a $ref found in the document. Kind is the type of object
+// referenced, e.g. schema or parameter.
+type indexedRef struct {
+	Pointer string
+	Ref     string
+	Kind    string
+}
+
+// indexedExtension is a specification extension (x-*) found in the document
+type indexedExtension struct {
+	Pointer string
+	Name    string
+	Value   interface{}
+}
+
+// getDocIndex returns the index of the parsed contents of the specified file
+func getDocIndex(ctx context.Context, d *plugin.QueryData, path string) (*docIndex, error) {
+	h := &plugin.HydrateData{Item: path}
+	i, err := getDocIndexCached(ctx, d, h)
+	if err != nil {
+		return nil, err
+	}
+	return i.(*docIndex), nil
+}
+
+// Cached form of getDocIndex, see getDocCached.
+var getDocIndexCached = plugin.HydrateFunc(getDocIndexUncached).Memoize(memoize.WithCacheKeyFunction(getDocIndexCacheKey))
+
+func getDocIndexCacheKey(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	path := h.Item.(string)
+	key := fmt.Sprintf("getDocIndex-%s", path)
+	return key, nil
+}
+
+// getDocIndexUncached builds the index for a path. Do not call this
+// directly, use getDocIndex instead.
+func getDocIndexUncached(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	path := h.Item.(string)
+
+	doc, err := getDoc(ctx, d, path)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildDocIndex(doc), nil
+}
+
+// components returns the components declared in a section, in name order
+func (idx *docIndex) components(section string) []*indexedComponent {
+	var result []*indexedComponent
+	for _, c := range idx.Components {
+		if c.Section == section {
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
+func buildDocIndex(doc *openapi3.T) *docIndex {
+	b := &docIndexBuilder{idx: &docIndex{Doc: doc}}
+
+	b.extensions("", doc.Extensions)
+	if doc.Info != nil {
+		b.extensions("/info", doc.Info.Extensions)
+	}
+
+	for _, apiPath := range sortedKeys(doc.Paths) {
+		b.pathItem(pointerJoin("/paths", apiPath), apiPath, doc.Paths[apiPath])
+	}
+
+	if c := doc.Components; c != nil {
+		b.extensions("/components", c.Extensions)
+		for _, name := range sortedKeys(c.Schemas) {
+			ptr := b.component("schemas", name, c.Schemas[name])
+			b.idx.Schemas = append(b.idx.Schemas, &indexedSchema{Pointer: ptr, Name: name, Schema: c.Schemas[name]})
+			b.schema(ptr, c.Schemas[name])
+		}
+		for _, name := range sortedKeys(c.Parameters) {
+			b.parameter(b.component("parameters", name, c.Parameters[name]), c.Parameters[name])
+		}
+		for _, name := range sortedKeys(c.Headers) {
+			b.header(b.component("headers", name, c.Headers[name]), c.Headers[name])
+		}
+		for _, name := range sortedKeys(c.RequestBodies) {
+			b.requestBody(b.component("requestBodies", name, c.RequestBodies[name]), c.RequestBodies[name])
+		}
+		for _, name := range sortedKeys(c.Responses) {
+			b.response(b.component("responses", name, c.Responses[name]), c.Responses[name])
+		}
+		for _, name := range sortedKeys(c.SecuritySchemes) {
+			ref := c.SecuritySchemes[name]
+			ptr := b.component("securitySchemes", name, ref)
+			if ref == nil || b.ref(ptr, ref.Ref, "securityScheme") || ref.Value == nil {
+				continue
+			}
+			b.extensions(ptr, ref.Value.Extensions)
+		}
+		for _, name := range sortedKeys(c.Examples) {
+			b.example(b.component("examples", name, c.Examples[name]), "component", name, c.Examples[name], nil)
+		}
+		for _, name := range sortedKeys(c.Links) {
+			b.link(b.component("links", name, c.Links[name]), c.Links[name])
+		}
+		for _, name := range sortedKeys(c.Callbacks) {
+			b.callback(b.component("callbacks", name, c.Callbacks[name]), c.Callbacks[name])
+		}
+	}
+
+	return b.idx
+}
+
+// docIndexBuilder walks a document and records its contents in idx. The walk
+// never follows a $ref, so each object is visited once at the place it is
+// declared and reference cycles cannot cause infinite recursion.
+type docIndexBuilder struct {
+	idx *docIndex
+	// context is the context of the examples found, set while walking a
+	// request body or a response
+	context string
+}
+
+// ref records a $ref, returning true if there was one to record
+func (b *docIndexBuilder) ref(ptr string, ref string, kind string) bool {
+	if ref == "" {
+		return false
+	}
+	b.idx.Refs = append(b.idx.Refs, &indexedRef{Pointer: ptr, Ref: ref, Kind: kind})
+	return true
+}
+
+// mappingRef records a reference made other than by $ref
+func (b *docIndexBuilder) mappingRef(ptr string, ref string, kind string) {
+	if ref == "" {
+		return
+	}
+	b.idx.MappingRefs = append(b.idx.MappingRefs, &indexedRef{Pointer: ptr, Ref: ref, Kind: kind})
+}
+
+// component records an object declared in components, returning its pointer
+func (b *docIndexBuilder) component(section string, name string, ref interface{}) string {
+	ptr := pointerJoin("/components", section, name)
+	b.idx.Components = append(b.idx.Components, &indexedComponent{Pointer: ptr, Section: section, Name: name, Ref: ref})
+	return ptr
+}
+
+func (b *docIndexBuilder) extensions(ptr string, extensions map[string]interface{}) {
+	for _, name := range sortedKeys(extensions) {
+		b.idx.Extensions = append(b.idx.Extensions, &indexedExtension{Pointer: pointerJoin(ptr, name), Name: name, Value: extensions[name]})
+	}
+}
+
+func (b *docIndexBuilder) pathItem(ptr string, apiPath string, item *openapi3.PathItem) {
+	if item == nil {
+		return
+	}
+	// A $ref to a path item is resolved in place, with Ref left set, so its
+	// operations are indexed where they are used
+	b.ref(ptr, item.Ref, "pathItem")
+	b.extensions(ptr, item.Extensions)
+
+	for i, param := range item.Parameters {
+		paramPtr := pointerJoin(ptr, "parameters", fmt.Sprint(i))
+		b.idx.Parameters = append(b.idx.Parameters, &indexedParameter{Pointer: paramPtr, ApiPath: apiPath, Parameter: param})
+		b.parameter(paramPtr, param)
+	}
+
+	for _, method := range OperationTypes {
+		operation := getOperationInfoByType(method, item)
+		if operation == nil {
+			continue
+		}
+		opPtr := pointerJoin(ptr, method)
+		b.idx.Operations = append(b.idx.Operations, &indexedOperation{
+			Pointer:   opPtr,
+			ApiPath:   apiPath,
+			Method:    method,
+			PathItem:  item,
+			Operation: operation,
+		})
+		b.operation(opPtr, apiPath, method, operation)
+	}
+}
+
+func (b *docIndexBuilder) operation(ptr string, apiPath string, method string, operation *openapi3.Operation) {
+	b.extensions(ptr, operation.Extensions)
+
+	for i, param := range operation.Parameters {
+		paramPtr := pointerJoin(ptr, "parameters", fmt.Sprint(i))
+		b.idx.Parameters = append(b.idx.Parameters, &indexedParameter{Pointer: paramPtr, ApiPath: apiPath, Method: method, Parameter: param})
+		b.parameter(paramPtr, param)
+	}
+
+	if operation.RequestBody != nil {
+		b.requestBody(pointerJoin(ptr, "requestBody"), operation.RequestBody)
+	}
+
+	for _, status := range sortedKeys(operation.Responses) {
+		responsePtr := pointerJoin(ptr, "responses", status)
+		response := operation.Responses[status]
+		b.idx.Responses = append(b.idx.Responses, &indexedResponse{Pointer: responsePtr, ApiPath: apiPath, Method: method, Status: status, Response: response})
+		b.response(responsePtr, response)
+	}
+
+	for _, name := range sortedKeys(operation.Callbacks) {
+		b.callback(pointerJoin(ptr, "callbacks", name), operation.Callbacks[name])
+	}
+}
+
+func (b *docIndexBuilder) parameter(ptr string, ref *openapi3.ParameterRef) {
+	if ref == nil || b.ref(ptr, ref.Ref, "parameter") || ref.Value == nil {
+		return
+	}
+	b.parameterValue(ptr, "parameter", ref.Value)
+}
+
+func (b *docIndexBuilder) parameterValue(ptr string, location string, param *openapi3.Parameter) {
+	b.extensions(ptr, param.Extensions)
+	b.schema(pointerJoin(ptr, "schema"), param.Schema)
+	b.content(pointerJoin(ptr, "content"), param.Content)
+	b.exampleValue(pointerJoin(ptr, "example"), location, param.Example, param.Schema)
+	for _, name := range sortedKeys(param.Examples) {
+		b.example(pointerJoin(ptr, "examples", name), location, name, param.Examples[name], param.Schema)
+	}
+}
+
+func (b *docIndexBuilder) header(ptr string, ref *openapi3.HeaderRef) {
+	if ref == nil || b.ref(ptr, ref.Ref, "header") || ref.Value == nil {
+		return
+	}
+	b.parameterValue(ptr, "header", &ref.Value.Parameter)
+}
+
+func (b *docIndexBuilder) requestBody(ptr string, ref *openapi3.RequestBodyRef) {
+	if ref == nil || b.ref(ptr, ref.Ref, "requestBody") || ref.Value == nil {
+		return
+	}
+	defer b.withContext("request")()
+	b.extensions(ptr, ref.Value.Extensions)
+	b.content(pointerJoin(ptr, "content"), ref.Value.Content)
+}
+
+func (b *docIndexBuilder) response(ptr string, ref *openapi3.ResponseRef) {
+	if ref == nil || b.ref(ptr, ref.Ref, "response") || ref.Value == nil {
+		return
+	}
+	defer b.withContext("response")()
+	b.extensions(ptr, ref.Value.Extensions)
+	for _, name := range sortedKeys(ref.Value.Headers) {
+		b.header(pointerJoin(ptr, "headers", name), ref.Value.Headers[name])
+	}
+	b.content(pointerJoin(ptr, "content"), ref.Value.Content)
+	for _, name := range sortedKeys(ref.Value.Links) {
+		b.link(pointerJoin(ptr, "links", name), ref.Value.Links[name])
+	}
+}
+
+// withContext sets the context of the examples found until the returned
+// function is called, which restores the previous one
+func (b *docIndexBuilder) withContext(context string) func() {
+	previous := b.context
+	b.context = context
+	return func() { b.context = previous }
+}
+
+func (b *docIndexBuilder) content(ptr string, content openapi3.Content) {
+	for _, mediaType := range sortedKeys(content) {
+		mt := content[mediaType]
+		if mt == nil {
+			continue
+		}
+		mtPtr := pointerJoin(ptr, mediaType)
+		b.extensions(mtPtr, mt.Extensions)
+		b.schema(pointerJoin(mtPtr, "schema"), mt.Schema)
+		b.exampleValue(pointerJoin(mtPtr, "example"), "media_type", mt.Example, mt.Schema)
+		for _, name := range sortedKeys(mt.Examples) {
+			b.example(pointerJoin(mtPtr, "examples", name), "media_type", name, mt.Examples[name], mt.Schema)
+		}
+		for _, name := range sortedKeys(mt.Encoding) {
+			if encoding := mt.Encoding[name]; encoding != nil {
+				encodingPtr := pointerJoin(mtPtr, "encoding", name)
+				b.extensions(encodingPtr, encoding.Extensions)
+				for _, header := range sortedKeys(encoding.Headers) {
+					b.header(pointerJoin(encodingPtr, "headers", header), encoding.Headers[header])
+				}
+			}
+		}
+	}
+}
+
+// example records an entry of an examples map. References to component
+// examples are recorded too, with the value they resolve to, since they are
+// examples of the object they are declared on.
+func (b *docIndexBuilder) example(ptr string, location string, name string, ref *openapi3.ExampleRef, schema *openapi3.SchemaRef) {
+	if ref == nil {
+		return
+	}
+	example := &indexedExample{Pointer: ptr, Location: location, Context: b.context, Name: name, Example: ref, Schema: schema}
+	if ref.Value != nil {
+		example.Value = ref.Value.Value
+	}
+	b.idx.Examples = append(b.idx.Examples, example)
+
+	if b.ref(ptr, ref.Ref, "example") || ref.Value == nil {
+		return
+	}
+	b.extensions(ptr, ref.Value.Extensions)
+}
+
+// exampleValue records an example field, if set
+func (b *docIndexBuilder) exampleValue(ptr string, location string, value interface{}, schema *openapi3.SchemaRef) {
+	if value == nil {
+		return
+	}
+	b.idx.Examples = append(b.idx.Examples, &indexedExample{Pointer: ptr, Location: location, Context: b.context, Value: value, Schema: schema})
+}
+
+func (b *docIndexBuilder) link(ptr string, ref *openapi3.LinkRef) {
+	if ref == nil || b.ref(ptr, ref.Ref, "link") || ref.Value == nil {
+		return
+	}
+	b.extensions(ptr, ref.Value.Extensions)
+	b.mappingRef(pointerJoin(ptr, "operationRef"), ref.Value.OperationRef, "operation")
+}
+
+func (b *docIndexBuilder) callback(ptr string, ref *openapi3.CallbackRef) {
+	if ref == nil || b.ref(ptr, ref.Ref, "callback") || ref.Value == nil {
+		return
+	}
+	for _, expression := range sortedKeys(*ref.Value) {
+		item := (*ref.Value)[expression]
+		itemPtr := pointerJoin(ptr, expression)
+		if item == nil || b.ref(itemPtr, item.Ref, "pathItem") {
+			continue
+		}
+		// Callback operations are not operations of the API itself, so only
+		// their contents are indexed.
+		b.extensions(itemPtr, item.Extensions)
+		for i, param := range item.Parameters {
+			b.parameter(pointerJoin(itemPtr, "parameters", fmt.Sprint(i)), param)
+		}
+		for _, method := range OperationTypes {
+			operation := getOperationInfoByType(method, item)
+			if operation == nil {
+				continue
+			}
+			b.callbackOperation(pointerJoin(itemPtr, method), operation)
+		}
+	}
+}
+
+func (b *docIndexBuilder) callbackOperation(ptr string, operation *openapi3.Operation) {
+	b.extensions(ptr, operation.Extensions)
+	for i, param := range operation.Parameters {
+		b.parameter(pointerJoin(ptr, "parameters", fmt.Sprint(i)), param)
+	}
+	if operation.RequestBody != nil {
+		b.requestBody(pointerJoin(ptr, "requestBody"), operation.RequestBody)
+	}
+	for _, status := range sortedKeys(operation.Responses) {
+		b.response(pointerJoin(ptr, "responses", status), operation.Responses[status])
+	}
+	for _, name := range sortedKeys(operation.Callbacks) {
+		b.callback(pointerJoin(ptr, "callbacks", name), operation.Callbacks[name])
+	}
+}
+
+func (b *docIndexBuilder) schema(ptr string, ref *openapi3.SchemaRef) {
+	if ref == nil || b.ref(ptr, ref.Ref, "schema") || ref.Value == nil {
+		return
+	}
+	s := ref.Value
+	b.extensions(ptr, s.Extensions)
+	b.exampleValue(pointerJoin(ptr, "example"), "schema", s.Example, ref)
+	if s.Discriminator != nil {
+		for _, key := range sortedKeys(s.Discriminator.Mapping) {
+			b.mappingRef(pointerJoin(ptr, "discriminator", "mapping", key), b.discriminatorRef(s.Discriminator.Mapping[key]), "schema")
+		}
+	}
+	for i, sub := range s.AllOf {
+		b.schema(pointerJoin(ptr, "allOf", fmt.Sprint(i)), sub)
+	}
+	for i, sub := range s.OneOf {
+		b.schema(pointerJoin(ptr, "oneOf", fmt.Sprint(i)), sub)
+	}
+	for i, sub := range s.AnyOf {
+		b.schema(pointerJoin(ptr, "anyOf", fmt.Sprint(i)), sub)
+	}
+	b.schema(pointerJoin(ptr, "not"), s.Not)
+	b.schema(pointerJoin(ptr, "items"), s.Items)
+	for _, name := range sortedKeys(s.Properties) {
+		b.schema(pointerJoin(ptr, "properties", name), s.Properties[name])
+	}
+	b.schema(pointerJoin(ptr, "additionalProperties"), s.AdditionalProperties.Schema)
+}
+
+// discriminatorRef returns the reference a discriminator mapping value is
+// for. A value is either the name of a schema in components or a reference.
+func (b *docIndexBuilder) discriminatorRef(value string) string {
+	if c := b.idx.Doc.Components; c != nil && !strings.HasPrefix(value, "#") {
+		if _, ok := c.Schemas[value]; ok {
+			return "#" + pointerJoin("/components/schemas", value)
+		}
+	}
+	return value
+}
+
+// pointerJoin appends the given reference tokens to a JSON pointer, escaping
+// them as described in RFC 6901.
+func pointerJoin(ptr string, tokens ...string) string {
+	var sb strings.Builder
+	sb.WriteString(ptr)
+	for _, token := range tokens {
+		sb.WriteString("/")
+		sb.WriteString(strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1"))
+	}
+	return sb.String()
+}
+
+// sortedKeys returns the keys of a map in sorted order, so that the index
+// and the rows built from it are stable between queries.
+func sortedKeys[V any, M ~map[string]V](m M) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
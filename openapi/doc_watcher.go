@@ -0,0 +1,241 @@
+package openapi
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"time"
+	"weak"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/hashicorp/go-hclog"
+	"github.com/turbot/steampipe-plugin-sdk/v5/connection"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+// docWatcherCheckInterval is how often a watcher checks that its connection
+// still exists
+const docWatcherCheckInterval = time.Minute
+
+// docWatcher watches the files read to load the documents of a connection.
+// It is the only file watcher of the connection, no config argument is
+// watched by the SDK, and changes are reported to the plugin's
+// WatchedFileChangedFunc, which invalidates only the cached documents
+// depending on the changed files.
+type docWatcher struct {
+	connectionName string
+	// connection is held weakly, so that a removed connection can be
+	// noticed and its watcher closed
+	connection weak.Pointer[plugin.Connection]
+	watcher    *fsnotify.Watcher
+
+	mutex sync.Mutex
+	cache *connection.ConnectionCache
+	// dependents maps the cleaned path of each watched file to the paths,
+	// as used in the document cache keys, of the documents read from it
+	dependents map[string]map[string]bool
+	dirs       map[string]struct{}
+}
+
+var (
+	docWatchersMutex sync.Mutex
+	docWatchers      = map[string]*docWatcher{}
+
+	// watchingPlugin is the plugin document watchers report changes to, set
+	// when the plugin is created
+	watchingPlugin *plugin.Plugin
+)
+
+// watchDocFile starts watching file for changes, as a file the document at
+// docPath is read from, e.g. the document itself or a file it references.
+// Files are watched before they are read, so that a change made while the
+// document is loading is not missed. Failures are logged, since the plugin
+// still works without the watch, it just doesn't notice changes until the
+// cache expires.
+func watchDocFile(ctx context.Context, d *plugin.QueryData, docPath string, file string) {
+	w, err := getDocWatcher(ctx, d)
+	if err != nil {
+		plugin.Logger(ctx).Warn("watchDocFile", "connection_name", d.Connection.Name, "watch_error", err)
+		return
+	}
+	if err := w.add(d.ConnectionCache, file, docPath); err != nil {
+		plugin.Logger(ctx).Warn("watchDocFile", "connection_name", d.Connection.Name, "path", docPath, "file", file, "watch_error", err)
+	}
+}
+
+func getDocWatcher(ctx context.Context, d *plugin.QueryData) (*docWatcher, error) {
+	docWatchersMutex.Lock()
+	defer docWatchersMutex.Unlock()
+
+	if w, ok := docWatchers[d.Connection.Name]; ok {
+		return w, nil
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	w := &docWatcher{
+		connectionName: d.Connection.Name,
+		connection:     weak.Make(d.Connection),
+		watcher:        watcher,
+		cache:          d.ConnectionCache,
+		dependents:     map[string]map[string]bool{},
+		dirs:           map[string]struct{}{},
+	}
+	go w.run(plugin.Logger(ctx))
+	docWatchers[d.Connection.Name] = w
+
+	return w, nil
+}
+
+// closeDocWatcher stops watching the files of the connection, e.g. when its
+// config changes. A new watcher is started by the next document load.
+func closeDocWatcher(connectionName string) {
+	docWatchersMutex.Lock()
+	w, ok := docWatchers[connectionName]
+	docWatchersMutex.Unlock()
+	if ok {
+		w.close()
+	}
+}
+
+// docFilesChanged is the WatchedFileChangedFunc of the plugin. The cached
+// documents depending on the changed files are invalidated, and the query
+// cache of the connection is cleared, since its results may come from those
+// documents or miss a file added to paths.
+func docFilesChanged(ctx context.Context, p *plugin.Plugin, conn *plugin.Connection, events []fsnotify.Event) {
+	docWatchersMutex.Lock()
+	w, ok := docWatchers[conn.Name]
+	docWatchersMutex.Unlock()
+
+	if ok {
+		for _, ev := range events {
+			w.invalidate(ctx, p.Logger, ev)
+		}
+	}
+	if err := p.ClearQueryCache(ctx, conn.Name); err != nil {
+		p.Logger.Warn("docFilesChanged", "connection_name", conn.Name, "cache_error", err)
+	}
+}
+
+// connectionConfigChanged is the ConnectionConfigChangedFunc of the plugin.
+// The watcher of the connection is closed, since the files it reads may have
+// changed, then the connection and query caches are cleared as they are by
+// default.
+func connectionConfigChanged(ctx context.Context, p *plugin.Plugin, old *plugin.Connection, new *plugin.Connection) error {
+	closeDocWatcher(old.Name)
+	if err := p.ClearConnectionCache(ctx, new.Name); err != nil {
+		return err
+	}
+	return p.ClearQueryCache(ctx, new.Name)
+}
+
+// add records that the document at docPath depends on file. The directory
+// containing file is watched rather than the file itself, so that editors
+// which save by renaming a new file into place are handled.
+func (w *docWatcher) add(cache *connection.ConnectionCache, file string, docPath string) error {
+	name, err := filepath.Abs(file)
+	if err != nil {
+		return err
+	}
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	// The connection cache is replaced if the connection is reloaded
+	w.cache = cache
+
+	if _, ok := w.dependents[name]; !ok {
+		dir := filepath.Dir(name)
+		if _, ok := w.dirs[dir]; !ok {
+			if err := w.watcher.Add(dir); err != nil {
+				return err
+			}
+			w.dirs[dir] = struct{}{}
+		}
+		w.dependents[name] = map[string]bool{}
+	}
+	w.dependents[name][docPath] = true
+
+	return nil
+}
+
+// affects returns true if ev may change query results, i.e. it is a change
+// to a watched file, or a new file which may be matched by paths.
+func (w *docWatcher) affects(ev fsnotify.Event) bool {
+	if !ev.Has(fsnotify.Write) && !ev.Has(fsnotify.Create) && !ev.Has(fsnotify.Remove) && !ev.Has(fsnotify.Rename) {
+		return false
+	}
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	_, ok := w.dependents[filepath.Clean(ev.Name)]
+	return ok || ev.Has(fsnotify.Create)
+}
+
+// invalidate removes the cached documents depending on the file of ev
+func (w *docWatcher) invalidate(ctx context.Context, logger hclog.Logger, ev fsnotify.Event) {
+	w.mutex.Lock()
+	var paths []string
+	for path := range w.dependents[filepath.Clean(ev.Name)] {
+		paths = append(paths, path)
+	}
+	cache := w.cache
+	w.mutex.Unlock()
+
+	for _, path := range paths {
+		invalidateDoc(ctx, cache, path)
+		logger.Debug("docWatcher.invalidate", "connection_name", w.connectionName, "path", path, "file", ev.Name, "event", ev.Op.String(), "status", "invalidated")
+	}
+}
+
+// close stops the watcher and removes it from the watchers, unless it has
+// been replaced already
+func (w *docWatcher) close() {
+	docWatchersMutex.Lock()
+	if docWatchers[w.connectionName] == w {
+		delete(docWatchers, w.connectionName)
+	}
+	docWatchersMutex.Unlock()
+
+	w.watcher.Close()
+}
+
+func (w *docWatcher) run(logger hclog.Logger) {
+	ticker := time.NewTicker(docWatcherCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case ev, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			if !w.affects(ev) {
+				continue
+			}
+			conn := w.connection.Value()
+			if conn == nil || watchingPlugin == nil {
+				continue
+			}
+			watchingPlugin.WatchedFileChangedFunc(context.Background(), watchingPlugin, conn, []fsnotify.Event{ev})
+
+		case <-ticker.C:
+			// The plugin holds its connections for as long as they exist, so
+			// a connection only reachable from here has been removed
+			if w.connection.Value() == nil {
+				logger.Debug("docWatcher.run", "connection_name", w.connectionName, "status", "connection removed")
+				w.close()
+				return
+			}
+
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			logger.Warn("docWatcher.run", "connection_name", w.connectionName, "watch_error", err)
+		}
+	}
+}
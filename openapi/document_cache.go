@@ -0,0 +1,353 @@
+package openapi
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/invopop/yaml"
+	filehelpers "github.com/turbot/go-kit/files"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+// documentCacheVersion is part of every cache key. Bump it whenever the
+// format of the cached data changes so stale entries are ignored.
+const documentCacheVersion = "v2"
+
+// documentCache is a disk-backed store of loaded OpenAPI documents, so that
+// a new session reuses the documents loaded by previous ones.
+//
+// An entry holds everything a document was loaded from, as JSON: the root
+// document with its overlays applied, and every file it references. Loading
+// from an entry reads no source files, decodes no YAML and applies no
+// overlays, only the JSON is parsed and references resolved in memory.
+// Documents fetched from URLs, or referencing them, are not cached, since
+// they are fetched on every load to notice changes.
+type documentCache struct {
+	dir string
+}
+
+// documentCacheEntry is a cached document
+type documentCacheEntry struct {
+	Document json.RawMessage `json:"document"`
+	// Files are the files read, the root document as read included, keyed
+	// by their location as resolved by the loader
+	Files          map[string]json.RawMessage `json:"files"`
+	OverlayActions []*overlayActionResult     `json:"overlay_actions"`
+}
+
+// documentCacheManifest records which cache entry belongs to a document and
+// options, along with the state of the files it was loaded from. It allows a
+// cache hit without reading any of the files again.
+type documentCacheManifest struct {
+	Path    string              `json:"path"`
+	Options string              `json:"options"`
+	Files   []documentCacheFile `json:"files"`
+	// InlineHash is the hash of the content of an inline spec
+	InlineHash string `json:"inline_hash,omitempty"`
+	Key        string `json:"key"`
+}
+
+// documentCacheFile is the state of a file a document was loaded from
+type documentCacheFile struct {
+	Path    string    `json:"path"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+}
+
+var (
+	documentCachesMutex sync.Mutex
+	documentCaches      = map[string]*documentCache{}
+)
+
+// getDocumentCache returns the shared document cache for the connection, or
+// nil if caching is not configured. The cache directory is created along with
+// the cache, the first time it is used for the connection and cache_dir.
+func getDocumentCache(d *plugin.QueryData) (*documentCache, error) {
+	config := GetConfig(d.Connection)
+	if config.CacheDir == nil || *config.CacheDir == "" {
+		return nil, nil
+	}
+
+	key := fmt.Sprintf("%s-%s", d.Connection.Name, *config.CacheDir)
+
+	documentCachesMutex.Lock()
+	defer documentCachesMutex.Unlock()
+
+	if c, ok := documentCaches[key]; ok {
+		return c, nil
+	}
+	c, err := newDocumentCache(*config.CacheDir)
+	if err != nil {
+		return nil, err
+	}
+	documentCaches[key] = c
+	return c, nil
+}
+
+// newDocumentCache returns a document cache stored in dir, creating the
+// directory if needed.
+func newDocumentCache(dir string) (*documentCache, error) {
+	dir, err := filehelpers.Tildefy(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(filepath.Join(dir, "manifests"), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create cache_dir %s: %v", dir, err)
+	}
+
+	return &documentCache{dir: dir}, nil
+}
+
+// documentCacheOptions returns a stable description of the options that
+// affect how a document is loaded.
+func documentCacheOptions(externalRefs bool, overlays []string) string {
+	return fmt.Sprintf("%s;external_refs=%t;overlays=%s", documentCacheVersion, externalRefs, strings.Join(overlays, ","))
+}
+
+// load returns the cached document at path, or nil if there is no entry or
+// any file it was loaded from has changed since. The files are watched as
+// if the document had been loaded from them.
+func (c *documentCache) load(ctx context.Context, d *plugin.QueryData, path string, options string, externalRefs bool) *loadedDoc {
+	manifest, _ := c.readManifest(path, options)
+	if manifest == nil {
+		return nil
+	}
+
+	for _, file := range manifest.Files {
+		watchDocFile(ctx, d, path, file.Path)
+	}
+	if content, ok := GetConfig(d.Connection).InlineSpecs[path]; ok && manifest.InlineHash != documentCacheHash([]byte(content)) {
+		return nil
+	}
+	for _, file := range manifest.Files {
+		info, err := os.Stat(file.Path)
+		if err != nil || info.Size() != file.Size || !info.ModTime().Equal(file.ModTime) {
+			return nil
+		}
+	}
+
+	data, err := os.ReadFile(c.entryPath(manifest.Key))
+	if err != nil {
+		return nil
+	}
+	var entry documentCacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil
+	}
+
+	// References are resolved against the files in the entry only
+	loader := openapi3.NewLoader()
+	loader.Context = ctx
+	loader.IsExternalRefsAllowed = externalRefs
+	loader.ReadFromURIFunc = func(_ *openapi3.Loader, location *url.URL) ([]byte, error) {
+		if data, ok := entry.Files[location.String()]; ok {
+			return data, nil
+		}
+		return nil, fmt.Errorf("%s is not in the cache entry", location.String())
+	}
+	doc, err := loader.LoadFromDataWithPath(entry.Document, &url.URL{Path: filepath.ToSlash(path)})
+	if err != nil {
+		return nil
+	}
+
+	return &loadedDoc{Doc: doc, OverlayActions: entry.OverlayActions}
+}
+
+// documentCacheRecorder records what a document is loaded from, to store it
+// in the cache once loaded
+type documentCacheRecorder struct {
+	cache *documentCache
+	path  string
+
+	mutex sync.Mutex
+	files []documentCacheFile
+	data  map[string][]byte
+	// uncacheable is set if anything was read from a URL, or a file could not
+	// be stated, since the entry could not be checked
+	uncacheable bool
+}
+
+func (c *documentCache) recorder(path string) *documentCacheRecorder {
+	return &documentCacheRecorder{cache: c, path: path, data: map[string][]byte{}}
+}
+
+// wrap makes loader record the files it reads. Files are stated before they
+// are read, so that a change made while reading is noticed on the next load.
+func (r *documentCacheRecorder) wrap(d *plugin.QueryData, loader *openapi3.Loader) {
+	config := GetConfig(d.Connection)
+	read := loader.ReadFromURIFunc
+	loader.ReadFromURIFunc = func(l *openapi3.Loader, location *url.URL) ([]byte, error) {
+		_, inline := config.InlineSpecs[location.Path]
+		if location.Host == "" && !inline {
+			r.stat(filepath.FromSlash(location.Path))
+		}
+		data, err := read(l, location)
+		if err != nil {
+			return nil, err
+		}
+
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+		if location.Host != "" {
+			r.uncacheable = true
+		}
+		r.data[location.String()] = data
+		return data, nil
+	}
+}
+
+// stat records the state of a file the document is loaded from
+func (r *documentCacheRecorder) stat(path string) {
+	info, err := os.Stat(path)
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if err != nil {
+		// Without a state the entry could never be checked, so is not stored
+		r.uncacheable = true
+		return
+	}
+	r.files = append(r.files, documentCacheFile{Path: path, Size: info.Size(), ModTime: info.ModTime()})
+}
+
+// store writes the loaded document to the cache. document is the root
+// document with its overlays applied, or nil to use the root as read. The
+// cache is best effort, a failed write only costs a load next time.
+func (r *documentCacheRecorder) store(d *plugin.QueryData, options string, document []byte, overlayActions []*overlayActionResult) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.uncacheable {
+		return
+	}
+	root := (&url.URL{Path: filepath.ToSlash(r.path)}).String()
+	if document == nil {
+		document = r.data[root]
+	}
+	entry := documentCacheEntry{
+		Document:       json.RawMessage(documentCacheJSON(document)),
+		Files:          map[string]json.RawMessage{},
+		OverlayActions: overlayActions,
+	}
+	for location, data := range r.data {
+		entry.Files[location] = json.RawMessage(documentCacheJSON(data))
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+
+	manifest := documentCacheManifest{
+		Path:    r.path,
+		Options: options,
+		Files:   r.files,
+		Key:     documentCacheHash([]byte(options), []byte(r.path), data),
+	}
+	if content, ok := GetConfig(d.Connection).InlineSpecs[r.path]; ok {
+		manifest.InlineHash = documentCacheHash([]byte(content))
+	}
+
+	if err := writeFileAtomic(r.cache.entryPath(manifest.Key), data); err != nil {
+		return
+	}
+	previous, _ := r.cache.readManifest(r.path, options)
+	r.cache.writeManifest(manifest, previous)
+}
+
+// documentCacheJSON returns data, a document as read, as JSON
+func documentCacheJSON(data []byte) []byte {
+	if json.Valid(data) {
+		return data
+	}
+	converted, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return data
+	}
+	return converted
+}
+
+func (c *documentCache) readManifest(path string, options string) (*documentCacheManifest, error) {
+	data, err := os.ReadFile(c.manifestPath(path, options))
+	if err != nil {
+		return nil, err
+	}
+	var manifest documentCacheManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, err
+	}
+	// Guard against hash collisions on the manifest file name
+	if manifest.Path != path || manifest.Options != options {
+		return nil, nil
+	}
+	return &manifest, nil
+}
+
+// writeManifest records manifest as the current entry for its document and
+// options. The entry the previous manifest pointed to is removed, since the
+// document has changed, so there is at most one entry for each document and
+// options.
+func (c *documentCache) writeManifest(manifest documentCacheManifest, previous *documentCacheManifest) {
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return
+	}
+	if err := writeFileAtomic(c.manifestPath(manifest.Path, manifest.Options), data); err != nil {
+		return
+	}
+	if previous != nil && previous.Key != manifest.Key {
+		os.Remove(c.entryPath(previous.Key))
+	}
+}
+
+func (c *documentCache) entryPath(key string) string {
+	return filepath.Join(c.dir, key+".json")
+}
+
+func (c *documentCache) manifestPath(path string, options string) string {
+	return filepath.Join(c.dir, "manifests", documentCacheHash([]byte(path), []byte(options))+".json")
+}
+
+// documentCacheHash returns the hash of parts, separated so that moving
+// bytes from one part to the next changes the hash
+func documentCacheHash(parts ...[]byte) string {
+	h := sha256.New()
+	for _, part := range parts {
+		h.Write(part)
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// writeFileAtomic writes data to a temporary file and renames it into place,
+// so concurrent readers never see a partially written entry.
+func writeFileAtomic(name string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(name), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	if err := os.Rename(f.Name(), name); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return nil
+}
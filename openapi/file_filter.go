@@ -0,0 +1,166 @@
+package openapi
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	filehelpers "github.com/turbot/go-kit/files"
+)
+
+// specRootFields are the fields which identify the root of an OpenAPI or
+// Swagger definition.
+var specRootFields = []string{"openapi", "swagger"}
+
+// excludePatterns returns the exclude_paths globs as absolute patterns, as
+// expected by filehelpers.Match. Patterns starting with ** match anywhere
+// and are left as is.
+func excludePatterns(config openAPIConfig) ([]string, error) {
+	var patterns []string
+	for _, pattern := range config.ExcludePaths {
+		if !strings.HasPrefix(pattern, "**") {
+			tildefied, err := filehelpers.Tildefy(pattern)
+			if err != nil {
+				return nil, err
+			}
+			if pattern, err = filepath.Abs(tildefied); err != nil {
+				return nil, err
+			}
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns, nil
+}
+
+// isExcludedPath returns true if path matches any of the exclude patterns
+func isExcludedPath(path string, patterns []string) bool {
+	if len(patterns) == 0 {
+		return false
+	}
+	name, err := filepath.Abs(path)
+	if err != nil {
+		name = path
+	}
+	return !filehelpers.ShouldIncludePath(name, nil, patterns)
+}
+
+// isSpecFile reports whether the root of the document at path has an openapi
+// or swagger field. Only the top level of the document is inspected, so this
+// is cheap even for large files.
+func isSpecFile(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	first, err := firstNonSpace(r)
+	if err != nil {
+		return false
+	}
+	if first == '{' {
+		return jsonHasRootField(r)
+	}
+	return yamlHasRootField(r)
+}
+
+// firstNonSpace returns the first non whitespace byte of r, without consuming it
+func firstNonSpace(r *bufio.Reader) (byte, error) {
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		if b != ' ' && b != '\t' && b != '\r' && b != '\n' && b != 0xEF && b != 0xBB && b != 0xBF {
+			return b, r.UnreadByte()
+		}
+	}
+}
+
+// jsonHasRootField walks the top level keys of a JSON object, skipping over
+// their values without decoding them.
+func jsonHasRootField(r *bufio.Reader) bool {
+	dec := json.NewDecoder(r)
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return false
+	}
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return false
+		}
+		for _, field := range specRootFields {
+			if key == field {
+				return true
+			}
+		}
+		if err := skipJSONValue(dec); err != nil {
+			return false
+		}
+	}
+	return false
+}
+
+func skipJSONValue(dec *json.Decoder) error {
+	depth := 0
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch tok {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
+
+// yamlHasRootField looks for a top level key, i.e. one with no indentation,
+// matching one of the spec root fields.
+func yamlHasRootField(r *bufio.Reader) bool {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		for _, field := range specRootFields {
+			for _, key := range []string{field, `"` + field + `"`, `'` + field + `'`} {
+				if rest, ok := bytes.CutPrefix(line, []byte(key)); ok && bytes.HasPrefix(bytes.TrimLeft(rest, " \t"), []byte(":")) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// matchesConfigKey returns true if key, from a config map keyed by entries of
+// paths, applies to the document at path. A key applies if it is the path
+// itself, e.g. a URL or inline_specs name, or a glob matching the path.
+func matchesConfigKey(key string, path string) bool {
+	return key == path || (!isHTTPURL(key) && matchesPathPattern(key, path))
+}
+
+func matchesPathPattern(pattern string, path string) bool {
+	pattern, err := filehelpers.Tildefy(pattern)
+	if err != nil {
+		return false
+	}
+	pattern, err = filepath.Abs(pattern)
+	if err != nil {
+		return false
+	}
+	name, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	return filehelpers.Match(pattern, name)
+}
@@ -0,0 +1,116 @@
+package openapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// harLog is the part of an HTTP Archive (HAR) file used to validate traffic.
+// See http://www.softwareishard.com/blog/har-12-spec/
+type harLog struct {
+	Log struct {
+		Entries []*harEntry `json:"entries"`
+	} `json:"log"`
+}
+
+type harEntry struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+}
+
+type harRequest struct {
+	Method   string       `json:"method"`
+	URL      string       `json:"url"`
+	Headers  []harNameVal `json:"headers"`
+	PostData *struct {
+		MimeType string       `json:"mimeType"`
+		Text     string       `json:"text"`
+		Params   []harNameVal `json:"params"`
+	} `json:"postData"`
+}
+
+type harResponse struct {
+	// Status is 0 if no response was received, e.g. the request was aborted
+	Status  int          `json:"status"`
+	Headers []harNameVal `json:"headers"`
+	Content struct {
+		MimeType string `json:"mimeType"`
+		Text     string `json:"text"`
+		Encoding string `json:"encoding"`
+	} `json:"content"`
+}
+
+type harNameVal struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+func readHAR(path string) (*harLog, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	har := &harLog{}
+	if err := json.Unmarshal(data, har); err != nil {
+		return nil, err
+	}
+	return har, nil
+}
+
+// httpRequest returns the recorded request of the entry
+func (e *harEntry) httpRequest(ctx context.Context) (*http.Request, error) {
+	var body []byte
+	if p := e.Request.PostData; p != nil {
+		body = []byte(p.Text)
+		if p.Text == "" && len(p.Params) > 0 {
+			values := url.Values{}
+			for _, param := range p.Params {
+				values.Add(param.Name, param.Value)
+			}
+			body = []byte(values.Encode())
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, e.Request.Method, e.Request.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header = harHeader(e.Request.Headers)
+	if p := e.Request.PostData; p != nil && req.Header.Get("Content-Type") == "" && p.MimeType != "" {
+		req.Header.Set("Content-Type", p.MimeType)
+	}
+	return req, nil
+}
+
+// responseBody returns the recorded response of the entry
+func (e *harEntry) responseBody() (http.Header, []byte, error) {
+	header := harHeader(e.Response.Headers)
+	content := e.Response.Content
+	if header.Get("Content-Type") == "" && content.MimeType != "" {
+		header.Set("Content-Type", content.MimeType)
+	}
+	if content.Encoding == "base64" {
+		body, err := base64.StdEncoding.DecodeString(content.Text)
+		return header, body, err
+	}
+	return header, []byte(content.Text), nil
+}
+
+// harHeader returns recorded headers, without the pseudo headers of HTTP/2,
+// e.g. :authority
+func harHeader(headers []harNameVal) http.Header {
+	result := http.Header{}
+	for _, h := range headers {
+		if strings.HasPrefix(h.Name, ":") {
+			continue
+		}
+		result.Add(h.Name, h.Value)
+	}
+	return result
+}
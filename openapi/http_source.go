@@ -0,0 +1,185 @@
+package openapi
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+// defaultURLTimeout is the timeout for fetching a definition over HTTP when
+// url_timeout is not set
+const defaultURLTimeout = 30 * time.Second
+
+// isHTTPURL returns true if path is an HTTP or HTTPS URL rather than a file
+func isHTTPURL(path string) bool {
+	return strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://")
+}
+
+// maxURLRedirects is the number of redirects followed when fetching a URL
+const maxURLRedirects = 10
+
+// sharedHTTPClient is the HTTP client of a connection, along with the
+// options it was created with
+type sharedHTTPClient struct {
+	key    string
+	client *http.Client
+}
+
+var (
+	httpClientsMutex sync.Mutex
+	httpClients      = map[string]*sharedHTTPClient{}
+)
+
+// getHTTPClient returns the shared HTTP client for the connection, so that
+// connections are reused across loads. A new client is created whenever the
+// url_* options in the connection config change.
+func getHTTPClient(d *plugin.QueryData) (*http.Client, error) {
+	config := GetConfig(d.Connection)
+	key, err := json.Marshal([]interface{}{
+		config.URLs, config.URLHeaders, config.URLBearerToken, config.URLTimeout,
+		config.URLInsecureSkipVerify, config.URLCACertFile, config.MaxLoadMemoryMB,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	httpClientsMutex.Lock()
+	defer httpClientsMutex.Unlock()
+
+	shared, ok := httpClients[d.Connection.Name]
+	if ok && shared.key == string(key) {
+		return shared.client, nil
+	}
+	client, err := newHTTPClient(config)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		shared.client.CloseIdleConnections()
+	}
+	httpClients[d.Connection.Name] = &sharedHTTPClient{key: string(key), client: client}
+	return client, nil
+}
+
+// urlHosts returns the hosts of the configured urls, the only hosts sent the
+// url_headers and url_bearer_token
+func urlHosts(config openAPIConfig) map[string]bool {
+	hosts := map[string]bool{}
+	for _, u := range config.URLs {
+		if parsed, err := url.Parse(u); err == nil {
+			hosts[parsed.Host] = true
+		}
+	}
+	return hosts
+}
+
+// newHTTPClient returns a client using the url_* options in the connection
+// config. The url_headers and url_bearer_token are removed from redirects to
+// hosts other than those of the configured urls.
+func newHTTPClient(config openAPIConfig) (*http.Client, error) {
+	timeout := defaultURLTimeout
+	if config.URLTimeout != nil {
+		timeout = time.Duration(*config.URLTimeout) * time.Second
+	}
+
+	tlsConfig := &tls.Config{}
+	if config.URLInsecureSkipVerify != nil && *config.URLInsecureSkipVerify {
+		tlsConfig.InsecureSkipVerify = true
+	}
+	if config.URLCACertFile != nil {
+		pem, err := os.ReadFile(*config.URLCACertFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read url_ca_cert_file: %v", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in url_ca_cert_file %s", *config.URLCACertFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	hosts := urlHosts(config)
+	checkRedirect := func(req *http.Request, via []*http.Request) error {
+		if len(via) >= maxURLRedirects {
+			return fmt.Errorf("stopped after %d redirects", maxURLRedirects)
+		}
+		if !hosts[req.URL.Host] {
+			for k := range config.URLHeaders {
+				req.Header.Del(k)
+			}
+			req.Header.Del("Authorization")
+		}
+		return nil
+	}
+
+	return &http.Client{Timeout: timeout, Transport: transport, CheckRedirect: checkRedirect}, nil
+}
+
+// readFromHTTP returns a ReadFromURIFunc reading remote HTTP URIs with the
+// given client. The url_headers and url_bearer_token are only sent to the
+// hosts of the configured urls, so credentials never leak to other hosts
+// referenced by a definition. Responses larger than max_load_memory_mb fail.
+func readFromHTTP(config openAPIConfig, client *http.Client) openapi3.ReadFromURIFunc {
+	hosts := urlHosts(config)
+
+	budget := loadMemoryBudget(config)
+
+	return func(_ *openapi3.Loader, location *url.URL) ([]byte, error) {
+		if location.Scheme == "" || location.Host == "" {
+			return nil, openapi3.ErrURINotSupported
+		}
+		req, err := http.NewRequest(http.MethodGet, location.String(), nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Accept", "application/json, application/yaml;q=0.9, */*;q=0.8")
+		if hosts[location.Host] {
+			for k, v := range config.URLHeaders {
+				req.Header.Set(k, v)
+			}
+			if config.URLBearerToken != nil {
+				req.Header.Set("Authorization", "Bearer "+*config.URLBearerToken)
+			}
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode > 399 {
+			return nil, fmt.Errorf("error loading %q: request returned status code %d", location.String(), resp.StatusCode)
+		}
+		if budget <= 0 {
+			return io.ReadAll(resp.Body)
+		}
+
+		// Remote documents are held to the same memory budget as files,
+		// without trusting the Content-Length
+		data, err := io.ReadAll(io.LimitReader(resp.Body, budget+1))
+		if err != nil {
+			return nil, err
+		}
+		if int64(len(data)) > budget {
+			return nil, fmt.Errorf("error loading %q: response exceeds max_load_memory_mb", location.String())
+		}
+		return data, nil
+	}
+}
@@ -0,0 +1,221 @@
+package openapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+const testDefinition = `{"openapi":"3.0.0","info":{"title":"Petstore","version":"1.0.0"},"paths":{}}`
+
+// recordingServer serves body for every request and records the headers of
+// the last request it received
+type recordingServer struct {
+	*httptest.Server
+
+	mutex  sync.Mutex
+	header http.Header
+}
+
+func newRecordingServer(t *testing.T, status int, body string) *recordingServer {
+	s := &recordingServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mutex.Lock()
+		s.header = r.Header.Clone()
+		s.mutex.Unlock()
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *recordingServer) lastHeader() http.Header {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.header
+}
+
+func readTestURL(t *testing.T, config openAPIConfig, location string) ([]byte, error) {
+	t.Helper()
+	client, err := newHTTPClient(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse(location)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return readFromHTTP(config, client)(openapi3.NewLoader(), u)
+}
+
+func TestReadFromHTTPFetchesDefinition(t *testing.T) {
+	server := newRecordingServer(t, http.StatusOK, testDefinition)
+	config := openAPIConfig{URLs: []string{server.URL + "/openapi.json"}}
+
+	data, err := readTestURL(t, config, server.URL+"/openapi.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != testDefinition {
+		t.Errorf("got %q, want %q", data, testDefinition)
+	}
+
+	// The definition loads through the same reader used by the plugin
+	client, err := newHTTPClient(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loader := openapi3.NewLoader()
+	loader.ReadFromURIFunc = readFromHTTP(config, client)
+	u, _ := url.Parse(server.URL + "/openapi.json")
+	doc, err := loader.LoadFromURI(u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.Info.Title != "Petstore" {
+		t.Errorf("got title %q, want %q", doc.Info.Title, "Petstore")
+	}
+}
+
+func TestReadFromHTTPSendsHeadersAndBearerToken(t *testing.T) {
+	server := newRecordingServer(t, http.StatusOK, testDefinition)
+	token := "secret-token"
+	config := openAPIConfig{
+		URLs:           []string{server.URL + "/openapi.json"},
+		URLHeaders:     map[string]string{"X-Api-Key": "key"},
+		URLBearerToken: &token,
+	}
+
+	if _, err := readTestURL(t, config, server.URL+"/openapi.json"); err != nil {
+		t.Fatal(err)
+	}
+	header := server.lastHeader()
+	if got := header.Get("X-Api-Key"); got != "key" {
+		t.Errorf("got X-Api-Key %q, want %q", got, "key")
+	}
+	if got := header.Get("Authorization"); got != "Bearer secret-token" {
+		t.Errorf("got Authorization %q, want %q", got, "Bearer secret-token")
+	}
+
+	// Other paths of a configured host, e.g. referenced files, are sent the
+	// same headers
+	if _, err := readTestURL(t, config, server.URL+"/common.json"); err != nil {
+		t.Fatal(err)
+	}
+	if got := server.lastHeader().Get("Authorization"); got != "Bearer secret-token" {
+		t.Errorf("got Authorization %q for another path of the host, want %q", got, "Bearer secret-token")
+	}
+}
+
+func TestReadFromHTTPScopesCredentialsToConfiguredHosts(t *testing.T) {
+	configured := newRecordingServer(t, http.StatusOK, testDefinition)
+	other := newRecordingServer(t, http.StatusOK, testDefinition)
+	token := "secret-token"
+	config := openAPIConfig{
+		URLs:           []string{configured.URL + "/openapi.json"},
+		URLHeaders:     map[string]string{"X-Api-Key": "key"},
+		URLBearerToken: &token,
+	}
+
+	if _, err := readTestURL(t, config, other.URL+"/common.json"); err != nil {
+		t.Fatal(err)
+	}
+	header := other.lastHeader()
+	if got := header.Get("X-Api-Key"); got != "" {
+		t.Errorf("got X-Api-Key %q sent to an unconfigured host", got)
+	}
+	if got := header.Get("Authorization"); got != "" {
+		t.Errorf("got Authorization %q sent to an unconfigured host", got)
+	}
+}
+
+func TestReadFromHTTPRemovesCredentialsOnRedirectToOtherHosts(t *testing.T) {
+	other := newRecordingServer(t, http.StatusOK, testDefinition)
+	configured := httptest.NewServer(http.RedirectHandler(other.URL+"/openapi.json", http.StatusFound))
+	t.Cleanup(configured.Close)
+	token := "secret-token"
+	config := openAPIConfig{
+		URLs:           []string{configured.URL + "/openapi.json"},
+		URLHeaders:     map[string]string{"X-Api-Key": "key"},
+		URLBearerToken: &token,
+	}
+
+	data, err := readTestURL(t, config, configured.URL+"/openapi.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != testDefinition {
+		t.Errorf("got %q, want %q", data, testDefinition)
+	}
+	header := other.lastHeader()
+	if got := header.Get("X-Api-Key"); got != "" {
+		t.Errorf("got X-Api-Key %q sent to the redirect host", got)
+	}
+	if got := header.Get("Authorization"); got != "" {
+		t.Errorf("got Authorization %q sent to the redirect host", got)
+	}
+}
+
+func TestReadFromHTTPKeepsCredentialsOnRedirectToConfiguredHosts(t *testing.T) {
+	target := newRecordingServer(t, http.StatusOK, testDefinition)
+	redirect := httptest.NewServer(http.RedirectHandler(target.URL+"/openapi.json", http.StatusFound))
+	t.Cleanup(redirect.Close)
+	config := openAPIConfig{
+		URLs:       []string{redirect.URL + "/openapi.json", target.URL + "/openapi.json"},
+		URLHeaders: map[string]string{"X-Api-Key": "key"},
+	}
+
+	if _, err := readTestURL(t, config, redirect.URL+"/openapi.json"); err != nil {
+		t.Fatal(err)
+	}
+	if got := target.lastHeader().Get("X-Api-Key"); got != "key" {
+		t.Errorf("got X-Api-Key %q, want %q", got, "key")
+	}
+}
+
+func TestReadFromHTTPErrors(t *testing.T) {
+	oneMB := 1
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		config openAPIConfig
+		want   string
+	}{
+		{
+			name:   "error status",
+			status: http.StatusNotFound,
+			body:   "not found",
+			want:   "status code 404",
+		},
+		{
+			name:   "response over max_load_memory_mb",
+			status: http.StatusOK,
+			body:   strings.Repeat(" ", 1024*1024+1),
+			config: openAPIConfig{MaxLoadMemoryMB: &oneMB},
+			want:   "exceeds max_load_memory_mb",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newRecordingServer(t, tt.status, tt.body)
+			_, err := readTestURL(t, tt.config, server.URL+"/openapi.json")
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got error %v, want one containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadFromHTTPIgnoresFiles(t *testing.T) {
+	_, err := readTestURL(t, openAPIConfig{}, "openapi.json")
+	if err != openapi3.ErrURINotSupported {
+		t.Errorf("got error %v, want %v", err, openapi3.ErrURINotSupported)
+	}
+}
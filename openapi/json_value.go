@@ -0,0 +1,86 @@
+package openapi
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// toJSONValue returns v as generic JSON values, i.e. maps, slices, strings,
+// float64, bool and nil. References in the model are kept as $ref objects.
+func toJSONValue(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// resolvePointer returns the value at the JSON pointer ptr in root
+func resolvePointer(root interface{}, ptr string) (interface{}, bool) {
+	if ptr == "" {
+		return root, true
+	}
+	if !strings.HasPrefix(ptr, "/") {
+		return nil, false
+	}
+	cursor := root
+	for _, token := range strings.Split(ptr[1:], "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		switch c := cursor.(type) {
+		case map[string]interface{}:
+			v, ok := c[token]
+			if !ok {
+				return nil, false
+			}
+			cursor = v
+		case []interface{}:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(c) {
+				return nil, false
+			}
+			cursor = c[i]
+		default:
+			return nil, false
+		}
+	}
+	return cursor, true
+}
+
+// inlineLocalRefs returns a copy of v with every local reference, i.e. one
+// starting with #, replaced by the value it points to in root. References
+// back into a value that is already being inlined are left as $ref objects,
+// so recursive structures stay finite. External references are left as is.
+func inlineLocalRefs(root interface{}, v interface{}) interface{} {
+	return inlineLocalRefsVisiting(root, v, map[string]bool{})
+}
+
+func inlineLocalRefsVisiting(root interface{}, v interface{}, visiting map[string]bool) interface{} {
+	switch c := v.(type) {
+	case map[string]interface{}:
+		if ref, ok := c["$ref"].(string); ok && strings.HasPrefix(ref, "#") && !visiting[ref] {
+			if target, ok := resolvePointer(root, ref[1:]); ok {
+				visiting[ref] = true
+				inlined := inlineLocalRefsVisiting(root, target, visiting)
+				delete(visiting, ref)
+				return inlined
+			}
+		}
+		m := make(map[string]interface{}, len(c))
+		for k, item := range c {
+			m[k] = inlineLocalRefsVisiting(root, item, visiting)
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(c))
+		for i, item := range c {
+			s[i] = inlineLocalRefsVisiting(root, item, visiting)
+		}
+		return s
+	}
+	return v
+}
@@ -0,0 +1,70 @@
+package openapi
+
+import (
+	"context"
+
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/turbot/go-kit/helpers"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+// mergeLabels returns the union of labels and more, with values in more
+// taking precedence
+func mergeLabels(labels map[string]string, more map[string]string) map[string]string {
+	if len(more) == 0 {
+		return labels
+	}
+	merged := make(map[string]string, len(labels)+len(more))
+	for k, v := range labels {
+		merged[k] = v
+	}
+	for k, v := range more {
+		merged[k] = v
+	}
+	return merged
+}
+
+// labels returns the attributes of the block as strings. Attributes which
+// cannot be converted to a string, e.g. lists, are ignored.
+func (b pathsBlock) labels() map[string]string {
+	labels := map[string]string{}
+	for name, attr := range b.Labels {
+		var value string
+		if diags := gohcl.DecodeExpression(attr.Expr, nil, &value); diags.HasErrors() {
+			continue
+		}
+		labels[name] = value
+	}
+	return labels
+}
+
+// labelsForPath returns the merged labels of every paths block whose pattern
+// is path, or a glob matching path, in the order of the blocks. It is the
+// one place labels are matched, whether the file was listed from paths or
+// requested through a qual.
+func labelsForPath(config openAPIConfig, path string) map[string]string {
+	var labels map[string]string
+	for _, b := range config.PathBlocks {
+		if matchesConfigKey(b.Pattern, path) {
+			labels = mergeLabels(labels, b.labels())
+		}
+	}
+	return labels
+}
+
+//// HYDRATE FUNCTIONS
+
+// getPathLabels returns the labels of the document a row belongs to. Rows of
+// tables listed from listOpenAPIFiles carry them in the parent item, other
+// rows must have a Path field.
+func getPathLabels(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	if parent, ok := h.ParentItem.(filePath); ok {
+		return parent.Labels, nil
+	}
+	value, _ := helpers.GetFieldValueFromInterface(h.Item, "Path")
+	path, ok := value.(string)
+	if !ok {
+		return nil, nil
+	}
+	return labelsForPath(GetConfig(d.Connection), path), nil
+}
@@ -0,0 +1,123 @@
+package openapi
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+	"sync"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"golang.org/x/sync/semaphore"
+)
+
+// docLoadLimiter bounds the number of documents parsed at once, and the total
+// size of the files being parsed, for a connection.
+type docLoadLimiter struct {
+	workers *semaphore.Weighted
+	memory  *semaphore.Weighted
+	budget  int64
+}
+
+var (
+	docLoadLimitersMutex sync.Mutex
+	docLoadLimiters      = map[string]*docLoadLimiter{}
+)
+
+// getDocLoadLimiter returns the shared limiter for the connection. A new
+// limiter is created whenever the limits in the connection config change.
+func getDocLoadLimiter(d *plugin.QueryData) *docLoadLimiter {
+	config := GetConfig(d.Connection)
+
+	workers := runtime.NumCPU()
+	if config.MaxConcurrentLoads != nil && *config.MaxConcurrentLoads > 0 {
+		workers = *config.MaxConcurrentLoads
+	}
+	budget := loadMemoryBudget(config)
+
+	key := fmt.Sprintf("%s-%d-%d", d.Connection.Name, workers, budget)
+
+	docLoadLimitersMutex.Lock()
+	defer docLoadLimitersMutex.Unlock()
+
+	if l, ok := docLoadLimiters[key]; ok {
+		return l
+	}
+	l := &docLoadLimiter{
+		workers: semaphore.NewWeighted(int64(workers)),
+		budget:  budget,
+	}
+	if budget > 0 {
+		l.memory = semaphore.NewWeighted(budget)
+	}
+	docLoadLimiters[key] = l
+	return l
+}
+
+// loadMemoryBudget returns max_load_memory_mb in bytes, or zero if unlimited
+func loadMemoryBudget(config openAPIConfig) int64 {
+	if config.MaxLoadMemoryMB != nil && *config.MaxLoadMemoryMB > 0 {
+		return int64(*config.MaxLoadMemoryMB) * 1024 * 1024
+	}
+	return 0
+}
+
+// fits returns false if a file of the given size can never be loaded within
+// the memory budget.
+func (l *docLoadLimiter) fits(size int64) bool {
+	return l.budget == 0 || size <= l.budget
+}
+
+// acquire blocks until a worker and size bytes of the memory budget are
+// available. The returned function must be called to release them.
+func (l *docLoadLimiter) acquire(ctx context.Context, size int64) (func(), error) {
+	if !l.fits(size) {
+		return nil, fmt.Errorf("file size %d bytes exceeds max_load_memory_mb", size)
+	}
+	if err := l.workers.Acquire(ctx, 1); err != nil {
+		return nil, err
+	}
+	if l.memory != nil {
+		if err := l.memory.Acquire(ctx, size); err != nil {
+			l.workers.Release(1)
+			return nil, err
+		}
+	}
+	return func() {
+		if l.memory != nil {
+			l.memory.Release(size)
+		}
+		l.workers.Release(1)
+	}, nil
+}
+
+// docSize returns the size of the document at path, which is either one of
+// the inline_specs or a file.
+func docSize(d *plugin.QueryData, path string) int64 {
+	if content, ok := GetConfig(d.Connection).InlineSpecs[path]; ok {
+		return int64(len(content))
+	}
+	return fileSize(path)
+}
+
+// fileSize returns the size of the file at path, or zero if it cannot be
+// determined, e.g. the file does not exist.
+func fileSize(path string) int64 {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
+
+// oversizeReason returns why the document at path is skipped if it is too
+// large to load within the memory budget, logging a warning, or an empty
+// string if it fits. Oversize files are skipped rather than loaded.
+func oversizeReason(ctx context.Context, d *plugin.QueryData, path string) string {
+	size := docSize(d, path)
+	if getDocLoadLimiter(d).fits(size) {
+		return ""
+	}
+	plugin.Logger(ctx).Warn("oversizeReason", "connection_name", d.Connection.Name, "path", path, "size", size, "status", "skipped")
+	return fmt.Sprintf("file size %d bytes exceeds max_load_memory_mb", size)
+}
@@ -0,0 +1,203 @@
+package openapi
+
+import (
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// operationRouter matches concrete requests, e.g. GET /pets/42, to the
+// operations of a document.
+type operationRouter struct {
+	routes []*operationRoute
+	// basePaths are the paths of the server URLs, e.g. /v1, which requests
+	// may include before the API path
+	basePaths []string
+}
+
+// operationRoute is the compiled API path of one operation
+type operationRoute struct {
+	Operation *indexedOperation
+	segments  []*routeSegment
+}
+
+// routeSegment is a segment of an API path. Literal segments have no
+// parameters, others are matched with pattern, e.g. {id}.json
+type routeSegment struct {
+	literal string
+	params  []string
+	pattern *regexp.Regexp
+}
+
+// routeMatch is an operation matching a request, with the values of its path
+// parameters
+type routeMatch struct {
+	Operation  *indexedOperation
+	PathParams map[string]string
+	// BasePath is the server path removed from the request path to match
+	BasePath string
+}
+
+var routeParamPattern = regexp.MustCompile(`\{([^{}]+)\}`)
+
+func newOperationRouter(idx *docIndex) *operationRouter {
+	r := &operationRouter{}
+	for _, op := range idx.Operations {
+		route := &operationRoute{Operation: op}
+		for _, segment := range strings.Split(strings.TrimPrefix(op.ApiPath, "/"), "/") {
+			route.segments = append(route.segments, compileRouteSegment(segment))
+		}
+		r.routes = append(r.routes, route)
+	}
+
+	seen := map[string]bool{}
+	for _, server := range idx.Doc.Servers {
+		// Server variables in the path can not be known, so only literal
+		// base paths are used
+		if server == nil || strings.Contains(server.URL, "{") {
+			continue
+		}
+		u, err := url.Parse(server.URL)
+		if err != nil {
+			continue
+		}
+		if base := strings.TrimSuffix(u.Path, "/"); base != "" && !seen[base] {
+			seen[base] = true
+			r.basePaths = append(r.basePaths, base)
+		}
+	}
+	return r
+}
+
+func compileRouteSegment(segment string) *routeSegment {
+	matches := routeParamPattern.FindAllStringSubmatchIndex(segment, -1)
+	if len(matches) == 0 {
+		return &routeSegment{literal: segment}
+	}
+	s := &routeSegment{}
+	var pattern strings.Builder
+	pattern.WriteString("^")
+	last := 0
+	for _, m := range matches {
+		pattern.WriteString(regexp.QuoteMeta(segment[last:m[0]]))
+		pattern.WriteString("(.+?)")
+		s.params = append(s.params, segment[m[2]:m[3]])
+		last = m[1]
+	}
+	pattern.WriteString(regexp.QuoteMeta(segment[last:]))
+	pattern.WriteString("$")
+	s.pattern = regexp.MustCompile(pattern.String())
+	return s
+}
+
+// match returns the operations matching method and requestPath, best match
+// first. As described in the OpenAPI specification, paths with literal
+// segments take precedence over templated paths, e.g. /pets/mine is matched
+// before /pets/{id}. An empty method matches operations of any method. If no
+// operation matches the path as is, it is matched again without each server
+// base path.
+func (r *operationRouter) match(method string, requestPath string) []*routeMatch {
+	if i := strings.IndexAny(requestPath, "?#"); i >= 0 {
+		requestPath = requestPath[:i]
+	}
+	if !strings.HasPrefix(requestPath, "/") {
+		requestPath = "/" + requestPath
+	}
+
+	matches := r.matchPath(method, requestPath, "")
+	if len(matches) > 0 {
+		return matches
+	}
+	for _, base := range r.basePaths {
+		if rest, ok := strings.CutPrefix(requestPath, base); ok && (rest == "" || strings.HasPrefix(rest, "/")) {
+			if rest == "" {
+				rest = "/"
+			}
+			matches = append(matches, r.matchPath(method, rest, base)...)
+		}
+	}
+	sortRouteMatches(matches)
+	return matches
+}
+
+func (r *operationRouter) matchPath(method string, requestPath string, base string) []*routeMatch {
+	// Segments are compared decoded, e.g. /caf%C3%A9 matches /café. The
+	// path is split first so that an encoded slash stays within its segment.
+	segments := strings.Split(strings.TrimPrefix(requestPath, "/"), "/")
+	for i, segment := range segments {
+		if value, err := url.PathUnescape(segment); err == nil {
+			segments[i] = value
+		}
+	}
+
+	var matches []*routeMatch
+	for _, route := range r.routes {
+		if method != "" && !strings.EqualFold(method, route.Operation.Method) {
+			continue
+		}
+		if m := route.match(segments); m != nil {
+			m.BasePath = base
+			matches = append(matches, m)
+		}
+	}
+	sortRouteMatches(matches)
+	return matches
+}
+
+func (route *operationRoute) match(segments []string) *routeMatch {
+	if len(segments) != len(route.segments) {
+		return nil
+	}
+	m := &routeMatch{Operation: route.Operation, PathParams: map[string]string{}}
+	for i, segment := range route.segments {
+		if segment.pattern == nil {
+			if segments[i] != segment.literal {
+				return nil
+			}
+			continue
+		}
+		values := segment.pattern.FindStringSubmatch(segments[i])
+		if values == nil {
+			return nil
+		}
+		for j, name := range segment.params {
+			m.PathParams[name] = values[j+1]
+		}
+	}
+	return m
+}
+
+// sortRouteMatches orders matches with the most literal segments first,
+// comparing segments from left to right, then by API path and method so the
+// order is stable
+func sortRouteMatches(matches []*routeMatch) {
+	sort.SliceStable(matches, func(i, j int) bool {
+		a, b := matches[i].Operation, matches[j].Operation
+		if c := compareRouteLiterals(a.ApiPath, b.ApiPath); c != 0 {
+			return c > 0
+		}
+		if a.ApiPath != b.ApiPath {
+			return a.ApiPath < b.ApiPath
+		}
+		return a.Method < b.Method
+	})
+}
+
+// compareRouteLiterals returns a positive number if the first templated
+// segment of a comes after that of b, i.e. a is the more specific path.
+func compareRouteLiterals(a string, b string) int {
+	as := strings.Split(a, "/")
+	bs := strings.Split(b, "/")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		aLiteral := !strings.Contains(as[i], "{")
+		bLiteral := !strings.Contains(bs[i], "{")
+		if aLiteral != bLiteral {
+			if aLiteral {
+				return 1
+			}
+			return -1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,169 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/invopop/yaml"
+	"github.com/ohler55/ojg/jp"
+	filehelpers "github.com/turbot/go-kit/files"
+)
+
+// overlayDocument is an OpenAPI Overlay 1.0 document
+// See https://spec.openapis.org/overlay/v1.0.0.html
+type overlayDocument struct {
+	Overlay string          `json:"overlay"`
+	Actions []overlayAction `json:"actions"`
+}
+
+type overlayAction struct {
+	Target      string      `json:"target"`
+	Description string      `json:"description"`
+	Update      interface{} `json:"update"`
+	Remove      bool        `json:"remove"`
+}
+
+// overlayActionResult describes the outcome of applying an overlay action
+type overlayActionResult struct {
+	OverlayPath string
+	ActionIndex int
+	Target      string
+	Description string
+	Action      string
+	TargetCount int
+	Error       string
+}
+
+// overlaysForPath returns the overlay files configured for the document at
+// path, in the order they are applied.
+func overlaysForPath(config openAPIConfig, path string) []string {
+	var overlays []string
+	for _, key := range sortedKeys(config.Overlays) {
+		if matchesConfigKey(key, path) {
+			overlays = append(overlays, config.Overlays[key]...)
+		}
+	}
+	return overlays
+}
+
+func readOverlay(path string) (*overlayDocument, error) {
+	name, err := filehelpers.Tildefy(path)
+	if err != nil {
+		return nil, err
+	}
+	content, err := os.ReadFile(filepath.Clean(name))
+	if err != nil {
+		return nil, err
+	}
+	data, err := yaml.YAMLToJSON(content)
+	if err != nil {
+		return nil, err
+	}
+	var overlay overlayDocument
+	if err := json.Unmarshal(data, &overlay); err != nil {
+		return nil, err
+	}
+	if overlay.Overlay == "" {
+		return nil, fmt.Errorf("%s is not an overlay document, the overlay field is missing", path)
+	}
+	return &overlay, nil
+}
+
+// applyOverlays applies each overlay file in turn to root, a document as
+// generic JSON values. Actions which fail or match no targets are reported
+// in the results rather than failing the whole document.
+func applyOverlays(root interface{}, overlayPaths []string) (interface{}, []*overlayActionResult, error) {
+	var results []*overlayActionResult
+	for _, overlayPath := range overlayPaths {
+		overlay, err := readOverlay(overlayPath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load overlay %s: %v", overlayPath, err)
+		}
+		for i, action := range overlay.Actions {
+			var result *overlayActionResult
+			root, result = applyOverlayAction(root, action)
+			result.OverlayPath = overlayPath
+			result.ActionIndex = i
+			results = append(results, result)
+		}
+	}
+	return root, results, nil
+}
+
+func applyOverlayAction(root interface{}, action overlayAction) (interface{}, *overlayActionResult) {
+	result := &overlayActionResult{
+		Target:      action.Target,
+		Description: action.Description,
+		Action:      "update",
+	}
+	if action.Remove {
+		result.Action = "remove"
+	}
+
+	x, err := jp.ParseString(action.Target)
+	if err != nil {
+		result.Error = fmt.Sprintf("invalid target: %v", err)
+		return root, result
+	}
+	locations := x.Locate(root, 0)
+	result.TargetCount = len(locations)
+
+	// Work backwards, so removing an array element does not shift the
+	// elements matched by the remaining locations
+	for i := len(locations) - 1; i >= 0; i-- {
+		loc := locations[i]
+		if action.Remove {
+			// The root of the document can not be removed
+			if len(loc) < 2 {
+				continue
+			}
+			if root, err = loc.RemoveOne(root); err != nil {
+				result.Error = err.Error()
+				return root, result
+			}
+			continue
+		}
+
+		// Each target gets its own copy of the update, so that merging into
+		// one of them later does not change the others
+		merged := mergeOverlayValue(loc.First(root), copySampleValue(action.Update))
+		if len(loc) < 2 {
+			root = merged
+			continue
+		}
+		if err := loc.SetOne(root, merged); err != nil {
+			result.Error = err.Error()
+			return root, result
+		}
+	}
+
+	return root, result
+}
+
+// mergeOverlayValue merges update into target. Objects are merged
+// recursively, an update to an array is appended to it as a single element,
+// even if it is an array itself, and any other value is replaced.
+func mergeOverlayValue(target interface{}, update interface{}) interface{} {
+	switch t := target.(type) {
+	case map[string]interface{}:
+		u, ok := update.(map[string]interface{})
+		if !ok {
+			return update
+		}
+		for k, v := range u {
+			if existing, ok := t[k]; ok {
+				if _, isObject := existing.(map[string]interface{}); isObject {
+					t[k] = mergeOverlayValue(existing, v)
+					continue
+				}
+			}
+			t[k] = v
+		}
+		return t
+	case []interface{}:
+		return append(t, update)
+	}
+	return update
+}
@@ -17,20 +17,39 @@ func Plugin(ctx context.Context) *plugin.Plugin {
 		ConnectionConfigSchema: &plugin.ConnectionConfigSchema{
 			NewInstance: ConfigInstance,
 		},
+		ConnectionConfigChangedFunc: connectionConfigChanged,
+		WatchedFileChangedFunc:      docFilesChanged,
 		TableMap: map[string]*plugin.Table{
+			"openapi_access_log_hit":            tableOpenAPIAccessLogHit(ctx),
+			"openapi_component_example":         tableOpenAPIComponentExample(ctx),
 			"openapi_component_header":          tableOpenAPIComponentHeader(ctx),
 			"openapi_component_parameter":       tableOpenAPIComponentParameter(ctx),
 			"openapi_component_request_body":    tableOpenAPIComponentRequestBody(ctx),
 			"openapi_component_response":        tableOpenAPIComponentResponse(ctx),
 			"openapi_component_schema":          tableOpenAPIComponentSchema(ctx),
 			"openapi_component_security_scheme": tableOpenAPIComponentSecurityScheme(ctx),
+			"openapi_component_usage":           tableOpenAPIComponentUsage(ctx),
+			"openapi_document":                  tableOpenAPIDocument(ctx),
+			"openapi_drift":                     tableOpenAPIDrift(ctx),
+			"openapi_example":                   tableOpenAPIExample(ctx),
+			"openapi_example_validation":        tableOpenAPIExampleValidation(ctx),
 			"openapi_info":                      tableOpenAPIInfo(ctx),
+			"openapi_overlay_action":            tableOpenAPIOverlayAction(ctx),
 			"openapi_path":                      tableOpenAPIPath(ctx),
 			"openapi_path_request_body":         tableOpenAPIPathRequestBody(ctx),
 			"openapi_path_response":             tableOpenAPIPathResponse(ctx),
+			"openapi_ref_edge":                  tableOpenAPIRefEdge(ctx),
+			"openapi_route_match":               tableOpenAPIRouteMatch(ctx),
+			"openapi_schema_cycle":              tableOpenAPISchemaCycle(ctx),
+			"openapi_schema_duplicate":          tableOpenAPISchemaDuplicate(ctx),
 			"openapi_server":                    tableOpenAPIServer(ctx),
+			"openapi_stats":                     tableOpenAPIStats(ctx),
+			"openapi_traffic_conformance":       tableOpenAPITrafficConformance(ctx),
+			"openapi_validate_request":          tableOpenAPIValidateRequest(ctx),
+			"openapi_validate_response":         tableOpenAPIValidateResponse(ctx),
 		},
 	}
+	watchingPlugin = p
 
 	return p
 }
@@ -0,0 +1,261 @@
+package openapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+)
+
+// validationError is an error found validating a request or response.
+// Location is where the error is: path, query, header, cookie, body,
+// security or response. Name is the name of the parameter or header, and
+// Pointer the JSON pointer to the invalid value within the body.
+type validationError struct {
+	Location string `json:"location"`
+	Name     string `json:"name,omitempty"`
+	Pointer  string `json:"pointer,omitempty"`
+	Reason   string `json:"reason"`
+}
+
+// validationOptions reports every error rather than only the first, checks
+// that credentials are present for the security requirements, and reports
+// response statuses not documented for the operation.
+var validationOptions = &openapi3filter.Options{
+	MultiError:            true,
+	IncludeResponseStatus: true,
+	AuthenticationFunc:    checkCredentials,
+}
+
+// validateRequest validates req against the operation it matched
+func validateRequest(ctx context.Context, doc *openapi3.T, m *routeMatch, req *http.Request) (*openapi3filter.RequestValidationInput, error) {
+	input := validationInput(doc, m, req)
+	return input, openapi3filter.ValidateRequest(ctx, input)
+}
+
+// validationInput returns the input to validate req, or a response to it,
+// against the operation it matched
+func validationInput(doc *openapi3.T, m *routeMatch, req *http.Request) *openapi3filter.RequestValidationInput {
+	return &openapi3filter.RequestValidationInput{
+		Request:    req,
+		PathParams: m.PathParams,
+		Route: &routers.Route{
+			Spec:      doc,
+			Path:      m.Operation.ApiPath,
+			PathItem:  m.Operation.PathItem,
+			Method:    strings.ToUpper(m.Operation.Method),
+			Operation: m.Operation.Operation,
+		},
+		Options: validationOptions,
+	}
+}
+
+// validateResponse validates a response to the request of input
+func validateResponse(ctx context.Context, input *openapi3filter.RequestValidationInput, status int, header http.Header, body []byte) error {
+	if header == nil {
+		header = http.Header{}
+	}
+	return openapi3filter.ValidateResponse(ctx, &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: input,
+		Status:                 status,
+		Header:                 header,
+		Body:                   io.NopCloser(bytes.NewReader(body)),
+		Options:                validationOptions,
+	})
+}
+
+// newValidationRequest builds the request to validate. apiPath is the path
+// of the request, which may include a query string, and query holds more
+// query parameters. Values of query and header may be a single value or a
+// list of values.
+func newValidationRequest(method string, apiPath string, query map[string]interface{}, header map[string]interface{}, body interface{}, contentType string) (*http.Request, error) {
+	if !strings.HasPrefix(apiPath, "/") {
+		apiPath = "/" + apiPath
+	}
+	u, err := url.Parse("http://localhost" + apiPath)
+	if err != nil {
+		return nil, err
+	}
+	values := u.Query()
+	for name, value := range query {
+		for _, v := range stringValues(value) {
+			values.Add(name, v)
+		}
+	}
+	u.RawQuery = values.Encode()
+
+	data, err := encodeBody(body, contentType)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(strings.ToUpper(method), u.String(), bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header = validationHeader(header)
+	if body != nil && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	return req, nil
+}
+
+// validationHeader returns the headers given as a JSON object
+func validationHeader(header map[string]interface{}) http.Header {
+	result := http.Header{}
+	for name, value := range header {
+		for _, v := range stringValues(value) {
+			result.Add(name, v)
+		}
+	}
+	return result
+}
+
+// encodeBody returns body as sent with contentType. Strings are sent as is
+// unless the content is JSON, objects as form values for form content, and
+// anything else as JSON.
+func encodeBody(body interface{}, contentType string) ([]byte, error) {
+	if body == nil {
+		return nil, nil
+	}
+	mediaType := strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	if s, ok := body.(string); ok && !strings.Contains(mediaType, "json") {
+		return []byte(s), nil
+	}
+	if m, ok := body.(map[string]interface{}); ok && mediaType == "application/x-www-form-urlencoded" {
+		values := url.Values{}
+		for name, value := range m {
+			for _, v := range stringValues(value) {
+				values.Add(name, v)
+			}
+		}
+		return []byte(values.Encode()), nil
+	}
+	return json.Marshal(body)
+}
+
+// stringValues returns a JSON value as strings, one for each item of a list
+func stringValues(value interface{}) []string {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case string:
+		return []string{v}
+	case []interface{}:
+		var result []string
+		for _, item := range v {
+			result = append(result, stringValues(item)...)
+		}
+		return result
+	case map[string]interface{}:
+		data, _ := json.Marshal(v)
+		return []string{string(data)}
+	default:
+		return []string{fmt.Sprint(v)}
+	}
+}
+
+// checkCredentials checks that a request carries credentials for a security
+// scheme. The credentials themselves can not be verified.
+func checkCredentials(_ context.Context, input *openapi3filter.AuthenticationInput) error {
+	scheme := input.SecurityScheme
+	req := input.RequestValidationInput.Request
+
+	present := false
+	switch scheme.Type {
+	case "apiKey":
+		switch scheme.In {
+		case "header":
+			present = req.Header.Get(scheme.Name) != ""
+		case "query":
+			present = req.URL.Query().Get(scheme.Name) != ""
+		case "cookie":
+			_, err := req.Cookie(scheme.Name)
+			present = err == nil
+		}
+	case "http":
+		authType, _, _ := strings.Cut(req.Header.Get("Authorization"), " ")
+		present = strings.EqualFold(authType, scheme.Scheme)
+	case "oauth2", "openIdConnect":
+		authType, _, _ := strings.Cut(req.Header.Get("Authorization"), " ")
+		present = strings.EqualFold(authType, "bearer")
+	}
+	if !present {
+		return fmt.Errorf("credentials for security scheme %q are missing", input.SecuritySchemeName)
+	}
+	return nil
+}
+
+// validationErrors flattens the error returned by validation into a list of
+// individual errors
+func validationErrors(err error) []validationError {
+	if err == nil {
+		return []validationError{}
+	}
+
+	if multi, ok := err.(openapi3.MultiError); ok {
+		var result []validationError
+		for _, e := range multi {
+			result = append(result, validationErrors(e)...)
+		}
+		return result
+	}
+
+	var requestErr *openapi3filter.RequestError
+	if errors.As(err, &requestErr) {
+		base := validationError{Location: "body", Reason: requestErr.Reason}
+		if p := requestErr.Parameter; p != nil {
+			base.Location = p.In
+			base.Name = p.Name
+		}
+		return withSchemaErrors(base, requestErr.Err, err)
+	}
+
+	var responseErr *openapi3filter.ResponseError
+	if errors.As(err, &responseErr) {
+		return withSchemaErrors(validationError{Location: "response", Reason: responseErr.Reason}, responseErr.Err, err)
+	}
+
+	var securityErr *openapi3filter.SecurityRequirementsError
+	if errors.As(err, &securityErr) {
+		return []validationError{{Location: "security", Reason: err.Error()}}
+	}
+
+	return []validationError{{Reason: err.Error()}}
+}
+
+// withSchemaErrors returns an error for each schema error in cause, with the
+// location of base. If there are none, err is returned as a single error.
+func withSchemaErrors(base validationError, cause error, err error) []validationError {
+	var causes []error
+	var multi openapi3.MultiError
+	if errors.As(cause, &multi) {
+		causes = multi
+	} else if cause != nil {
+		causes = []error{cause}
+	}
+
+	var result []validationError
+	for _, c := range causes {
+		var schemaErr *openapi3.SchemaError
+		if errors.As(c, &schemaErr) {
+			e := base
+			e.Pointer = pointerJoin("", schemaErr.JSONPointer()...)
+			e.Reason = schemaErr.Reason
+			result = append(result, e)
+		}
+	}
+	if len(result) == 0 {
+		base.Reason = err.Error()
+		result = append(result, base)
+	}
+	return result
+}
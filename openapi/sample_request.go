@@ -0,0 +1,317 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// sampleMultipartBoundary is the boundary of multipart sample bodies, fixed
+// so that samples are stable
+const sampleMultipartBoundary = "sample-boundary"
+
+// sampleRequest is a request to an operation, built from its required
+// parameters, security requirements and request body
+type sampleRequest struct {
+	Method string
+	URL    *url.URL
+	// Headers are in the order they are sent
+	Headers [][2]string
+	Body    []byte
+}
+
+// newSampleRequest builds a request to an operation of doc, which was loaded
+// from docPath. Credentials are placeholders named after their security
+// scheme, e.g. <api_key>.
+func newSampleRequest(doc *openapi3.T, docPath string, template string, method string, item *openapi3.PathItem, op *openapi3.Operation) (*sampleRequest, error) {
+	g := newSampleGenerator(template+" "+strings.ToLower(method), true)
+
+	base, err := sampleServerURL(doc, docPath, item, op)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &sampleRequest{Method: strings.ToUpper(method)}
+	query := url.Values{}
+	var cookies []string
+
+	// Parameters of the operation override those of the path with the same
+	// name and location
+	params := map[string]*openapi3.Parameter{}
+	var keys []string
+	for _, list := range []openapi3.Parameters{item.Parameters, op.Parameters} {
+		for _, ref := range list {
+			if ref == nil || ref.Value == nil {
+				continue
+			}
+			key := ref.Value.In + ":" + ref.Value.Name
+			if _, ok := params[key]; !ok {
+				keys = append(keys, key)
+			}
+			params[key] = ref.Value
+		}
+	}
+
+	apiPath := template
+	for _, key := range keys {
+		param := params[key]
+		if !param.Required && param.In != openapi3.ParameterInPath {
+			continue
+		}
+		value := g.parameterValue(param)
+		switch param.In {
+		case openapi3.ParameterInPath:
+			apiPath = strings.ReplaceAll(apiPath, "{"+param.Name+"}", url.PathEscape(paramString(value)))
+		case openapi3.ParameterInQuery:
+			explode := param.Explode == nil || *param.Explode
+			switch v := value.(type) {
+			case []interface{}:
+				if explode {
+					for _, item := range v {
+						query.Add(param.Name, paramString(item))
+					}
+					continue
+				}
+			case map[string]interface{}:
+				if explode {
+					for _, k := range sortedKeys(v) {
+						query.Add(k, paramString(v[k]))
+					}
+					continue
+				}
+			}
+			query.Add(param.Name, paramString(value))
+		case openapi3.ParameterInHeader:
+			req.Headers = append(req.Headers, [2]string{param.Name, paramString(value)})
+		case openapi3.ParameterInCookie:
+			cookies = append(cookies, param.Name+"="+paramString(value))
+		}
+	}
+
+	// Credentials for the first set of security requirements
+	security := doc.Security
+	if op.Security != nil {
+		security = *op.Security
+	}
+	if requiresAuthentication(security) && doc.Components != nil {
+		requirement := security[0]
+		for _, name := range sortedKeys(requirement) {
+			ref := doc.Components.SecuritySchemes[name]
+			if ref == nil || ref.Value == nil {
+				continue
+			}
+			scheme := ref.Value
+			placeholder := "<" + name + ">"
+			switch scheme.Type {
+			case "apiKey":
+				switch scheme.In {
+				case "header":
+					req.Headers = append(req.Headers, [2]string{scheme.Name, placeholder})
+				case "query":
+					query.Add(scheme.Name, placeholder)
+				case "cookie":
+					cookies = append(cookies, scheme.Name+"="+placeholder)
+				}
+			case "http":
+				authType := "Bearer"
+				if scheme.Scheme != "" && !strings.EqualFold(scheme.Scheme, "bearer") {
+					authType = strings.ToUpper(scheme.Scheme[:1]) + scheme.Scheme[1:]
+				}
+				req.Headers = append(req.Headers, [2]string{"Authorization", authType + " " + placeholder})
+			case "oauth2", "openIdConnect":
+				req.Headers = append(req.Headers, [2]string{"Authorization", "Bearer " + placeholder})
+			}
+		}
+	}
+	if len(cookies) > 0 {
+		req.Headers = append(req.Headers, [2]string{"Cookie", strings.Join(cookies, "; ")})
+	}
+
+	if body := op.RequestBody; body != nil && body.Value != nil {
+		if contentType := sampleContentType(body.Value.Content); contentType != "" {
+			value, err := toJSONValue(g.mediaTypeValue(body.Value.Content[contentType]))
+			if err != nil {
+				return nil, err
+			}
+			data, contentType, err := sampleRequestBody(value, contentType)
+			if err != nil {
+				return nil, err
+			}
+			req.Headers = append(req.Headers, [2]string{"Content-Type", contentType})
+			req.Body = data
+		}
+	}
+
+	u, err := url.Parse(strings.TrimSuffix(base, "/") + apiPath)
+	if err != nil {
+		return nil, err
+	}
+	u.RawQuery = query.Encode()
+	req.URL = u
+	return req, nil
+}
+
+// parameterValue returns the example of a parameter, or the first of its
+// examples, or a value generated from its schema
+func (g *sampleGenerator) parameterValue(param *openapi3.Parameter) interface{} {
+	if param.Example != nil {
+		return param.Example
+	}
+	for _, name := range sortedKeys(param.Examples) {
+		if e := param.Examples[name]; e != nil && e.Value != nil && e.Value.Value != nil {
+			return e.Value.Value
+		}
+	}
+	if param.Schema != nil {
+		return g.value(param.Schema, 0)
+	}
+	if contentType := sampleContentType(param.Content); contentType != "" {
+		return g.mediaTypeValue(param.Content[contentType])
+	}
+	return nil
+}
+
+// sampleServerURL returns the first of the servers of the operation, else
+// those of its path, else those of the document, with each variable set to
+// its default. Relative URLs are resolved against the URL the document was
+// loaded from, or http://localhost for files.
+func sampleServerURL(doc *openapi3.T, docPath string, item *openapi3.PathItem, op *openapi3.Operation) (string, error) {
+	servers := doc.Servers
+	if len(item.Servers) > 0 {
+		servers = item.Servers
+	}
+	if op.Servers != nil && len(*op.Servers) > 0 {
+		servers = *op.Servers
+	}
+
+	serverURL := "/"
+	if len(servers) > 0 && servers[0] != nil {
+		serverURL = servers[0].URL
+		for name, variable := range servers[0].Variables {
+			if variable != nil {
+				serverURL = strings.ReplaceAll(serverURL, "{"+name+"}", variable.Default)
+			}
+		}
+	}
+
+	base := "http://localhost/"
+	if isHTTPURL(docPath) {
+		base = docPath
+	}
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return "", err
+	}
+	ref, err := url.Parse(serverURL)
+	if err != nil {
+		return "", err
+	}
+	return baseURL.ResolveReference(ref).String(), nil
+}
+
+// sampleRequestBody encodes the body of a sample request, returning the
+// Content-Type header to send it with
+func sampleRequestBody(value interface{}, contentType string) ([]byte, string, error) {
+	if contentType != "multipart/form-data" {
+		data, err := encodeBody(value, contentType)
+		return data, contentType, err
+	}
+
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	if err := w.SetBoundary(sampleMultipartBoundary); err != nil {
+		return nil, "", err
+	}
+	if fields, ok := value.(map[string]interface{}); ok {
+		for _, name := range sortedKeys(fields) {
+			if err := w.WriteField(name, paramString(fields[name])); err != nil {
+				return nil, "", err
+			}
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), w.FormDataContentType(), nil
+}
+
+// paramString formats a parameter value in the simple style, i.e. lists are
+// joined by commas
+func paramString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = paramString(item)
+		}
+		return strings.Join(items, ",")
+	case map[string]interface{}:
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+	return fmt.Sprint(value)
+}
+
+// curl returns the request as a curl command
+func (r *sampleRequest) curl() string {
+	var sb strings.Builder
+	sb.WriteString("curl")
+	if r.Method != "GET" {
+		sb.WriteString(" -X " + r.Method)
+	}
+	sb.WriteString(" " + shellQuote(r.URL.String()))
+	for _, h := range r.Headers {
+		sb.WriteString(" \\\n  -H " + shellQuote(h[0]+": "+h[1]))
+	}
+	if r.Body != nil {
+		sb.WriteString(" \\\n  --data-raw " + shellQuote(string(r.Body)))
+	}
+	return sb.String()
+}
+
+// httpie returns the request as an HTTPie command
+func (r *sampleRequest) httpie() string {
+	var sb strings.Builder
+	sb.WriteString("http " + r.Method + " " + shellQuote(r.URL.String()))
+	for _, h := range r.Headers {
+		sb.WriteString(" \\\n  " + shellQuote(h[0]+":"+h[1]))
+	}
+	if r.Body != nil {
+		sb.WriteString(" \\\n  --raw " + shellQuote(string(r.Body)))
+	}
+	return sb.String()
+}
+
+// http returns the request as sent over HTTP/1.1, with CRLF line endings
+// and the header block always ended by an empty line
+func (r *sampleRequest) http() string {
+	var sb strings.Builder
+	sb.WriteString(r.Method + " " + r.URL.RequestURI() + " HTTP/1.1\r\n")
+	sb.WriteString("Host: " + r.URL.Host + "\r\n")
+	for _, h := range r.Headers {
+		sb.WriteString(h[0] + ": " + h[1] + "\r\n")
+	}
+	if r.Body != nil {
+		sb.WriteString("Content-Length: " + strconv.Itoa(len(r.Body)) + "\r\n")
+	}
+	sb.WriteString("\r\n")
+	sb.Write(r.Body)
+	return sb.String()
+}
+
+// shellQuote quotes s as a single argument for POSIX shells
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
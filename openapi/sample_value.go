@@ -0,0 +1,467 @@
+package openapi
+
+import (
+	"encoding/base64"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"regexp/syntax"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// maxSampleDepth is the deepest nesting of objects and arrays generated, so
+// that samples of recursive schemas stay small
+const maxSampleDepth = 8
+
+// maxSampleInteger bounds the integers generated, which are exact as JSON
+// numbers up to 2^53
+const maxSampleInteger = 1 << 53
+
+// samplePatternAttempts is how many strings are generated from a pattern to
+// find one within the length limits of a schema
+const samplePatternAttempts = 50
+
+// sampleEpoch is the earliest date and time generated for date formats
+var sampleEpoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// sampleGenerator synthesizes values from schemas. Values are random within
+// the constraints of each schema, but the same seed always generates the
+// same values.
+type sampleGenerator struct {
+	rand *rand.Rand
+	// request is true for request bodies, which omit readOnly properties.
+	// Responses omit writeOnly properties.
+	request  bool
+	visiting map[*openapi3.Schema]bool
+}
+
+func newSampleGenerator(seed string, request bool) *sampleGenerator {
+	h := fnv.New64a()
+	h.Write([]byte(seed))
+	return &sampleGenerator{
+		rand:     rand.New(rand.NewSource(int64(h.Sum64()))),
+		request:  request,
+		visiting: map[*openapi3.Schema]bool{},
+	}
+}
+
+// sampleContentType returns the content type to generate a sample for,
+// preferring JSON
+func sampleContentType(content openapi3.Content) string {
+	types := sortedKeys(content)
+	for _, t := range types {
+		if t == "application/json" {
+			return t
+		}
+	}
+	for _, t := range types {
+		if strings.Contains(t, "json") {
+			return t
+		}
+	}
+	if len(types) > 0 {
+		return types[0]
+	}
+	return ""
+}
+
+// mediaTypeValue returns the example of a media type, or the first of its
+// examples, or a value generated from its schema
+func (g *sampleGenerator) mediaTypeValue(mt *openapi3.MediaType) interface{} {
+	if mt == nil {
+		return nil
+	}
+	if mt.Example != nil {
+		return mt.Example
+	}
+	for _, name := range sortedKeys(mt.Examples) {
+		if e := mt.Examples[name]; e != nil && e.Value != nil && e.Value.Value != nil {
+			return e.Value.Value
+		}
+	}
+	return g.value(mt.Schema, 0)
+}
+
+// value generates a value for a schema. The example or default of the schema
+// is used if it has one, then a value of its enum.
+func (g *sampleGenerator) value(ref *openapi3.SchemaRef, depth int) interface{} {
+	if ref == nil || ref.Value == nil {
+		return nil
+	}
+	s := ref.Value
+	if s.Example != nil {
+		return s.Example
+	}
+	if s.Default != nil {
+		return s.Default
+	}
+	if len(s.Enum) > 0 {
+		return s.Enum[g.rand.Intn(len(s.Enum))]
+	}
+	if g.visiting[s] || depth > maxSampleDepth {
+		return nil
+	}
+	g.visiting[s] = true
+	defer delete(g.visiting, s)
+
+	switch {
+	case len(s.AllOf) > 0:
+		return g.allOf(s, depth)
+	case len(s.OneOf) > 0:
+		return g.alternative(s, s.OneOf, depth)
+	case len(s.AnyOf) > 0:
+		return g.alternative(s, s.AnyOf, depth)
+	}
+
+	switch sampleType(s) {
+	case "object":
+		return g.object(s, depth)
+	case "array":
+		return g.array(s, depth)
+	case "string":
+		return g.string(s)
+	case "integer":
+		return g.integer(s)
+	case "number":
+		return g.number(s)
+	case "boolean":
+		return g.rand.Intn(2) == 0
+	}
+	return nil
+}
+
+// sampleType returns the type of a schema, inferred from its keywords if it
+// has none
+func sampleType(s *openapi3.Schema) string {
+	switch {
+	case s.Type != "":
+		return s.Type
+	case len(s.Properties) > 0 || s.AdditionalProperties.Schema != nil:
+		return "object"
+	case s.Items != nil:
+		return "array"
+	case s.Format != "" || s.Pattern != "" || s.MinLength > 0 || s.MaxLength != nil:
+		return "string"
+	case s.Min != nil || s.Max != nil || s.MultipleOf != nil:
+		return "number"
+	}
+	return ""
+}
+
+// allOf merges the objects generated for each schema. If a schema does not
+// generate an object, e.g. allOf of a single string schema, its value is used.
+func (g *sampleGenerator) allOf(s *openapi3.Schema, depth int) interface{} {
+	merged := map[string]interface{}{}
+	for _, branch := range s.AllOf {
+		v := g.value(branch, depth+1)
+		object, ok := v.(map[string]interface{})
+		if !ok {
+			if v != nil && len(s.AllOf) == 1 {
+				return v
+			}
+			continue
+		}
+		for k, pv := range object {
+			merged[k] = pv
+		}
+	}
+	if len(s.Properties) > 0 {
+		for k, pv := range g.object(s, depth).(map[string]interface{}) {
+			merged[k] = pv
+		}
+	}
+	return merged
+}
+
+// alternative generates a value for the first of the oneOf or anyOf schemas,
+// setting the discriminator property to the name of the schema
+func (g *sampleGenerator) alternative(s *openapi3.Schema, branches openapi3.SchemaRefs, depth int) interface{} {
+	branch := branches[0]
+	v := g.value(branch, depth+1)
+	object, ok := v.(map[string]interface{})
+	if !ok {
+		return v
+	}
+	// The value may be the example of the schema, which is not to be changed
+	object = copySampleValue(object).(map[string]interface{})
+	if len(s.Properties) > 0 {
+		for k, pv := range g.object(s, depth).(map[string]interface{}) {
+			object[k] = pv
+		}
+	}
+	if d := s.Discriminator; d != nil && d.PropertyName != "" && branch.Ref != "" {
+		name := schemaNameFromRef(branch.Ref)
+		for _, key := range sortedKeys(d.Mapping) {
+			if d.Mapping[key] == branch.Ref || d.Mapping[key] == name {
+				name = key
+				break
+			}
+		}
+		object[d.PropertyName] = name
+	}
+	return object
+}
+
+// copySampleValue returns a deep copy of a value decoded from JSON or YAML
+func copySampleValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		c := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			c[k] = copySampleValue(item)
+		}
+		return c
+	case []interface{}:
+		c := make([]interface{}, len(v))
+		for i, item := range v {
+			c[i] = copySampleValue(item)
+		}
+		return c
+	}
+	return v
+}
+
+func (g *sampleGenerator) object(s *openapi3.Schema, depth int) interface{} {
+	required := map[string]bool{}
+	for _, name := range s.Required {
+		required[name] = true
+	}
+
+	object := map[string]interface{}{}
+	for _, name := range sortedKeys(s.Properties) {
+		prop := s.Properties[name]
+		if prop == nil || prop.Value == nil {
+			continue
+		}
+		if (g.request && prop.Value.ReadOnly) || (!g.request && prop.Value.WriteOnly) {
+			continue
+		}
+		v := g.value(prop, depth+1)
+		if v == nil && !required[name] {
+			continue
+		}
+		object[name] = v
+	}
+	if len(s.Properties) == 0 && s.AdditionalProperties.Schema != nil {
+		if v := g.value(s.AdditionalProperties.Schema, depth+1); v != nil {
+			object["key"] = v
+		}
+	}
+	return object
+}
+
+func (g *sampleGenerator) array(s *openapi3.Schema, depth int) interface{} {
+	n := 1
+	if s.MinItems > 1 {
+		n = int(s.MinItems)
+	}
+	if s.MaxItems != nil && int(*s.MaxItems) < n {
+		n = int(*s.MaxItems)
+	}
+	items := []interface{}{}
+	for i := 0; i < n; i++ {
+		v := g.value(s.Items, depth+1)
+		if v == nil {
+			break
+		}
+		items = append(items, v)
+	}
+	return items
+}
+
+func (g *sampleGenerator) string(s *openapi3.Schema) interface{} {
+	n := g.rand.Intn(1000)
+	switch s.Format {
+	case "date":
+		return sampleEpoch.AddDate(0, 0, n).Format("2006-01-02")
+	case "date-time":
+		return sampleEpoch.Add(time.Duration(g.rand.Int63n(int64(365 * 24 * time.Hour)))).Truncate(time.Second).Format(time.RFC3339)
+	case "time":
+		return sampleEpoch.Add(time.Duration(g.rand.Int63n(int64(24 * time.Hour)))).Truncate(time.Second).Format("15:04:05Z07:00")
+	case "email":
+		return fmt.Sprintf("user%d@example.com", n)
+	case "uuid":
+		b := make([]byte, 16)
+		g.rand.Read(b)
+		b[6] = b[6]&0x0f | 0x40
+		b[8] = b[8]&0x3f | 0x80
+		return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+	case "uri", "url", "uri-reference", "iri":
+		return fmt.Sprintf("https://example.com/resources/%d", n)
+	case "hostname", "idn-hostname":
+		return fmt.Sprintf("host%d.example.com", n)
+	case "ipv4":
+		return fmt.Sprintf("192.0.2.%d", n%256)
+	case "ipv6":
+		return fmt.Sprintf("2001:db8::%x", n)
+	case "byte":
+		return base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("sample %d", n)))
+	}
+
+	if s.Pattern != "" {
+		if v, ok := g.pattern(s.Pattern, s.MinLength, s.MaxLength); ok {
+			return v
+		}
+	}
+
+	v := "string"
+	if s.Format == "password" {
+		v = "password"
+	}
+	for uint64(len(v)) < s.MinLength {
+		v += "x"
+	}
+	if s.MaxLength != nil && uint64(len(v)) > *s.MaxLength {
+		v = v[:*s.MaxLength]
+	}
+	return v
+}
+
+// pattern generates a string matching a regular expression, with between
+// minLength and maxLength characters. It returns false if the pattern is
+// invalid or no string of that length was generated.
+func (g *sampleGenerator) pattern(pattern string, minLength uint64, maxLength *uint64) (string, bool) {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return "", false
+	}
+	re = re.Simplify()
+
+	// Unbounded repetitions may repeat up to minLength times, so that long
+	// enough strings can be generated
+	for i := 0; i < samplePatternAttempts; i++ {
+		var sb strings.Builder
+		g.regexp(re, int(min(minLength, 256)), &sb)
+		n := uint64(utf8.RuneCountInString(sb.String()))
+		if n >= minLength && (maxLength == nil || n <= *maxLength) {
+			return sb.String(), true
+		}
+	}
+	return "", false
+}
+
+func (g *sampleGenerator) regexp(re *syntax.Regexp, stretch int, sb *strings.Builder) {
+	switch re.Op {
+	case syntax.OpLiteral:
+		sb.WriteString(string(re.Rune))
+	case syntax.OpCharClass:
+		sb.WriteRune(g.classRune(re.Rune))
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		sb.WriteRune(rune('a' + g.rand.Intn(26)))
+	case syntax.OpCapture:
+		g.regexp(re.Sub[0], stretch, sb)
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			g.regexp(sub, stretch, sb)
+		}
+	case syntax.OpAlternate:
+		g.regexp(re.Sub[g.rand.Intn(len(re.Sub))], stretch, sb)
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		min, max := re.Min, re.Max
+		switch re.Op {
+		case syntax.OpStar:
+			min, max = 0, -1
+		case syntax.OpPlus:
+			min, max = 1, -1
+		case syntax.OpQuest:
+			min, max = 0, 1
+		}
+		if max < 0 {
+			max = min + 3 + stretch
+		}
+		for i := min + g.rand.Intn(max-min+1); i > 0; i-- {
+			g.regexp(re.Sub[0], stretch, sb)
+		}
+	}
+}
+
+// classRune picks a rune of a character class, given as pairs of the first
+// and last rune of each range, preferring printable ASCII
+func (g *sampleGenerator) classRune(ranges []rune) rune {
+	var printable []rune
+	for i := 0; i+1 < len(ranges); i += 2 {
+		lo, hi := max(ranges[i], ' '+1), min(ranges[i+1], '~')
+		for r := lo; r <= hi; r++ {
+			if unicode.IsPrint(r) {
+				printable = append(printable, r)
+			}
+		}
+	}
+	if len(printable) > 0 {
+		return printable[g.rand.Intn(len(printable))]
+	}
+	if len(ranges) > 0 {
+		return ranges[0]
+	}
+	return 'x'
+}
+
+func (g *sampleGenerator) integer(s *openapi3.Schema) interface{} {
+	lo, hi := g.bounds(s)
+	// Clamped so that the range fits an int64
+	lo = math.Max(math.Ceil(lo), -maxSampleInteger)
+	hi = math.Min(math.Floor(hi), maxSampleInteger)
+	if s.ExclusiveMin && s.Min != nil && lo == *s.Min {
+		lo++
+	}
+	if s.ExclusiveMax && s.Max != nil && hi == *s.Max {
+		hi--
+	}
+	if m := s.MultipleOf; m != nil && *m >= 1 {
+		return int64(g.multiple(lo, hi, *m))
+	}
+	if hi < lo {
+		return int64(lo)
+	}
+	return int64(lo) + g.rand.Int63n(int64(hi-lo)+1)
+}
+
+func (g *sampleGenerator) number(s *openapi3.Schema) interface{} {
+	lo, hi := g.bounds(s)
+	if m := s.MultipleOf; m != nil && *m > 0 {
+		return g.multiple(lo, hi, *m)
+	}
+	if hi < lo {
+		return lo
+	}
+	// Rounded to two decimals, within the bounds
+	v := math.Round((lo+g.rand.Float64()*(hi-lo))*100) / 100
+	if v < lo || (s.ExclusiveMin && v == lo) {
+		v = math.Ceil(lo*100+1) / 100
+	}
+	if v > hi || (s.ExclusiveMax && v == hi) {
+		v = math.Floor(hi*100-1) / 100
+	}
+	return v
+}
+
+// bounds returns the minimum and maximum of a number schema, with a range of
+// 100 where either is missing
+func (g *sampleGenerator) bounds(s *openapi3.Schema) (float64, float64) {
+	switch {
+	case s.Min != nil && s.Max != nil:
+		return *s.Min, *s.Max
+	case s.Min != nil:
+		return *s.Min, *s.Min + 100
+	case s.Max != nil:
+		return math.Min(0, *s.Max-100), *s.Max
+	}
+	return 1, 100
+}
+
+// multiple returns a multiple of m between lo and hi, or the first multiple
+// above lo if there is none
+func (g *sampleGenerator) multiple(lo float64, hi float64, m float64) float64 {
+	first := math.Ceil(lo/m) * m
+	count := math.Floor((hi-first)/m) + 1
+	if count < 1 {
+		return first
+	}
+	return first + float64(g.rand.Int63n(int64(math.Min(count, 1000))))*m
+}
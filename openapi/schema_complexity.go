@@ -0,0 +1,151 @@
+package openapi
+
+import (
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// schemaComplexity holds size and shape metrics of the payloads described by
+// one or more schemas. Each schema is measured once however many times it is
+// referenced, so that metrics grow with the size of the definition rather
+// than of every payload it allows.
+type schemaComplexity struct {
+	// MaxDepth is the deepest nesting of properties, array items and map
+	// values. A schema without any is at depth 0.
+	MaxDepth int
+	// PropertyCount is the number of properties of all nested objects
+	PropertyCount int
+	// CompositionBranchCount is the number of allOf, oneOf and anyOf schemas
+	CompositionBranchCount int
+	// RefCount is the number of references, including those to schemas
+	// already measured
+	RefCount int
+	// FanOutScore is the number of scalar values in a payload, where each
+	// value counts once more for every array or map it is nested in
+	FanOutScore int
+}
+
+// measureSchema returns the complexity of a schema
+func measureSchema(ref *openapi3.SchemaRef) schemaComplexity {
+	c := &schemaComplexity{}
+	c.MaxDepth = c.walk(ref, 0, newSchemaWalk())
+	return *c
+}
+
+// measureOperation returns the complexity of the parameters, request body
+// and responses of an operation together, including references to
+// components other than schemas.
+func measureOperation(item *openapi3.PathItem, op *openapi3.Operation) schemaComplexity {
+	c := &schemaComplexity{}
+	w := newSchemaWalk()
+	measure := func(ref *openapi3.SchemaRef) {
+		if depth := c.walk(ref, 0, w); depth > c.MaxDepth {
+			c.MaxDepth = depth
+		}
+	}
+	measureContent := func(content openapi3.Content) {
+		for _, mediaType := range sortedKeys(content) {
+			if mt := content[mediaType]; mt != nil {
+				measure(mt.Schema)
+			}
+		}
+	}
+
+	for _, params := range []openapi3.Parameters{item.Parameters, op.Parameters} {
+		for _, param := range params {
+			if param == nil || param.Value == nil {
+				continue
+			}
+			if param.Ref != "" {
+				c.RefCount++
+			}
+			measure(param.Value.Schema)
+			measureContent(param.Value.Content)
+		}
+	}
+	if body := op.RequestBody; body != nil && body.Value != nil {
+		if body.Ref != "" {
+			c.RefCount++
+		}
+		measureContent(body.Value.Content)
+	}
+	for _, status := range sortedKeys(op.Responses) {
+		response := op.Responses[status]
+		if response == nil || response.Value == nil {
+			continue
+		}
+		if response.Ref != "" {
+			c.RefCount++
+		}
+		measureContent(response.Value.Content)
+	}
+
+	return *c
+}
+
+// schemaWalk holds the schemas being measured, to stop at recursive
+// references, and the depth of those already measured
+type schemaWalk struct {
+	visiting map[*openapi3.Schema]bool
+	depths   map[*openapi3.Schema]int
+}
+
+func newSchemaWalk() *schemaWalk {
+	return &schemaWalk{
+		visiting: map[*openapi3.Schema]bool{},
+		depths:   map[*openapi3.Schema]int{},
+	}
+}
+
+// walk adds the metrics of ref to c and returns its depth. arrays is the
+// number of arrays and maps the schema is nested in. A schema already
+// measured, or being measured, only adds its reference.
+func (c *schemaComplexity) walk(ref *openapi3.SchemaRef, arrays int, w *schemaWalk) int {
+	if ref == nil || ref.Value == nil {
+		return 0
+	}
+	if ref.Ref != "" {
+		c.RefCount++
+	}
+	v := ref.Value
+	if w.visiting[v] {
+		return 0
+	}
+	if depth, ok := w.depths[v]; ok {
+		return depth
+	}
+	w.visiting[v] = true
+	defer delete(w.visiting, v)
+
+	depth := 0
+	nested := func(sub *openapi3.SchemaRef, arrays int) {
+		if d := 1 + c.walk(sub, arrays, w); d > depth {
+			depth = d
+		}
+	}
+
+	for _, branches := range []openapi3.SchemaRefs{v.AllOf, v.OneOf, v.AnyOf} {
+		c.CompositionBranchCount += len(branches)
+		for _, sub := range branches {
+			if d := c.walk(sub, arrays, w); d > depth {
+				depth = d
+			}
+		}
+	}
+	c.PropertyCount += len(v.Properties)
+	for _, name := range sortedKeys(v.Properties) {
+		nested(v.Properties[name], arrays)
+	}
+	if v.Items != nil {
+		nested(v.Items, arrays+1)
+	}
+	if v.AdditionalProperties.Schema != nil {
+		nested(v.AdditionalProperties.Schema, arrays+1)
+	}
+
+	if len(v.AllOf)+len(v.OneOf)+len(v.AnyOf)+len(v.Properties) == 0 && v.Items == nil && v.AdditionalProperties.Schema == nil {
+		c.FanOutScore += arrays + 1
+	}
+
+	w.depths[v] = depth
+	return depth
+}
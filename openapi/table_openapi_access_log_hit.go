@@ -0,0 +1,196 @@
+package openapi
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+//// TABLE DEFINITION
+
+func tableOpenAPIAccessLogHit(ctx context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "openapi_access_log_hit",
+		Description: "The number of requests in access logs for each operation and response status, and for each undocumented route.",
+		List: &plugin.ListConfig{
+			Hydrate:    listOpenAPIAccessLogHits,
+			KeyColumns: plugin.OptionalColumns([]string{"path"}),
+		},
+		Columns: commonColumns([]*plugin.Column{
+			{Name: "method", Description: "The HTTP method of the requests.", Type: proto.ColumnType_STRING},
+			{Name: "documented", Description: "True if the requests match an operation of the definition.", Type: proto.ColumnType_BOOL},
+			{Name: "operation_api_path", Description: "The API path of the operation matching the requests, e.g. /pets/{petId}. Null for undocumented routes.", Type: proto.ColumnType_STRING, Transform: transform.FromField("OperationApiPath").NullIfZero()},
+			{Name: "operation_id", Description: "The operationId of the operation matching the requests.", Type: proto.ColumnType_STRING, Transform: transform.FromField("OperationId").NullIfZero()},
+			{Name: "request_path", Description: "The path of the requests, without the query string, for undocumented routes.", Type: proto.ColumnType_STRING, Transform: transform.FromField("RequestPath").NullIfZero()},
+			{Name: "status", Description: "The response status of the requests. Null for operations without requests.", Type: proto.ColumnType_INT, Transform: transform.FromField("Status").NullIfZero()},
+			{Name: "status_documented", Description: "True if the operation documents the status, through its own code, a range such as 4XX, or a default response. Null for undocumented routes and operations without requests.", Type: proto.ColumnType_BOOL},
+			{Name: "hit_count", Description: "The number of requests.", Type: proto.ColumnType_INT},
+			{Name: "path", Description: "Path to the definition file with the matching operation. Null for undocumented routes.", Type: proto.ColumnType_STRING, Transform: transform.FromField("Path").NullIfZero()},
+		}),
+	}
+}
+
+type openAPIAccessLogHit struct {
+	Path             string
+	Method           string
+	Documented       bool
+	OperationApiPath string
+	OperationId      string
+	RequestPath      string
+	Status           int
+	StatusDocumented *bool
+	HitCount         int
+}
+
+// accessLogHitKey groups requests of the same route and status
+type accessLogHitKey struct {
+	Path        string
+	Method      string
+	ApiPath     string
+	RequestPath string
+	Status      int
+}
+
+//// LIST FUNCTION
+
+func listOpenAPIAccessLogHits(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	logPaths, err := sourceFiles(d, GetConfig(d.Connection).AccessLogPaths)
+	if err != nil {
+		plugin.Logger(ctx).Error("openapi_access_log_hit.listOpenAPIAccessLogHits", "config_error", err)
+		return nil, err
+	}
+
+	routers, err := specRouters(ctx, d)
+	if err != nil {
+		plugin.Logger(ctx).Error("openapi_access_log_hit.listOpenAPIAccessLogHits", "parse_error", err)
+		return nil, err
+	}
+
+	hits := map[accessLogHitKey]int{}
+	for _, logPath := range logPaths {
+		skipped, err := readAccessLog(logPath, func(e accessLogEntry) {
+			documented := false
+			for _, r := range routers {
+				matches := r.Router.match(e.Method, e.Path)
+				if len(matches) == 0 {
+					continue
+				}
+				documented = true
+				op := matches[0].Operation
+				hits[accessLogHitKey{Path: r.Path, Method: strings.ToUpper(op.Method), ApiPath: op.ApiPath, Status: e.Status}]++
+			}
+			if !documented {
+				requestPath, _, _ := strings.Cut(e.Path, "?")
+				hits[accessLogHitKey{Method: e.Method, RequestPath: requestPath, Status: e.Status}]++
+			}
+		})
+		if err != nil {
+			plugin.Logger(ctx).Error("openapi_access_log_hit.listOpenAPIAccessLogHits", "log_error", err, "log_path", logPath)
+			return nil, err
+		}
+		if skipped > 0 {
+			plugin.Logger(ctx).Warn("openapi_access_log_hit.listOpenAPIAccessLogHits", "log_path", logPath, "skipped_lines", skipped)
+		}
+	}
+
+	for _, row := range accessLogHitRows(routers, hits) {
+		d.StreamListItem(ctx, row)
+
+		// Context may get cancelled due to manual cancellation or if the limit has been reached
+		if d.RowsRemaining(ctx) == 0 {
+			return nil, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// accessLogHitRows returns a row for each operation and status with hits,
+// one for each operation without hits, and then one for each undocumented
+// route and status
+func accessLogHitRows(routers []*specRouter, hits map[accessLogHitKey]int) []openAPIAccessLogHit {
+	// Group the hits by operation, and collect the undocumented ones, in a
+	// single pass over the hits
+	statuses := map[accessLogHitKey][]int{}
+	var undocumented []accessLogHitKey
+	for k := range hits {
+		if k.Path == "" {
+			undocumented = append(undocumented, k)
+			continue
+		}
+		op := k
+		op.Status = 0
+		statuses[op] = append(statuses[op], k.Status)
+	}
+
+	var rows []openAPIAccessLogHit
+	for _, r := range routers {
+		for _, op := range r.Idx.Operations {
+			key := accessLogHitKey{Path: r.Path, Method: strings.ToUpper(op.Method), ApiPath: op.ApiPath}
+			base := openAPIAccessLogHit{
+				Path:             r.Path,
+				Method:           key.Method,
+				Documented:       true,
+				OperationApiPath: op.ApiPath,
+				OperationId:      op.Operation.OperationID,
+			}
+
+			opStatuses := statuses[key]
+			if len(opStatuses) == 0 {
+				rows = append(rows, base)
+				continue
+			}
+			sort.Ints(opStatuses)
+			for _, status := range opStatuses {
+				key.Status = status
+				row := base
+				row.Status = status
+				row.HitCount = hits[key]
+				documented := statusDocumented(op.Operation.Responses, status)
+				row.StatusDocumented = &documented
+				rows = append(rows, row)
+			}
+		}
+	}
+
+	sort.Slice(undocumented, func(i, j int) bool {
+		a, b := undocumented[i], undocumented[j]
+		if a.RequestPath != b.RequestPath {
+			return a.RequestPath < b.RequestPath
+		}
+		if a.Method != b.Method {
+			return a.Method < b.Method
+		}
+		return a.Status < b.Status
+	})
+	for _, k := range undocumented {
+		rows = append(rows, openAPIAccessLogHit{
+			Method:      k.Method,
+			RequestPath: k.RequestPath,
+			Status:      k.Status,
+			HitCount:    hits[k],
+		})
+	}
+	return rows
+}
+
+// statusDocumented returns true if responses has the status code, a range
+// including it, e.g. 4XX, or a default response
+func statusDocumented(responses openapi3.Responses, status int) bool {
+	code := fmt.Sprint(status)
+	for key := range responses {
+		switch {
+		case key == code, key == "default":
+			return true
+		case len(key) == 3 && strings.EqualFold(key[1:], "XX") && key[0] == code[0]:
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,70 @@
+package openapi
+
+import (
+	"context"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+//// TABLE DEFINITION
+
+func tableOpenAPIComponentExample(ctx context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "openapi_component_example",
+		Description: "Components examples object.",
+		List: &plugin.ListConfig{
+			ParentHydrate: listOpenAPIFiles,
+			Hydrate:       listOpenAPIComponentExamples,
+			KeyColumns:    plugin.OptionalColumns([]string{"path"}),
+		},
+		Columns: commonColumns([]*plugin.Column{
+			{Name: "key", Description: "The key used to refer or search the example.", Type: proto.ColumnType_STRING},
+			{Name: "summary", Description: "A short summary of the example.", Type: proto.ColumnType_STRING},
+			{Name: "description", Description: "A long description of the example.", Type: proto.ColumnType_STRING},
+			{Name: "value", Description: "The example value.", Type: proto.ColumnType_JSON},
+			{Name: "external_value", Description: "A URL that points to the literal example, for examples that cannot easily be included in JSON or YAML documents.", Type: proto.ColumnType_STRING, Transform: transform.FromField("ExternalValue").NullIfZero()},
+			{Name: "path", Description: "Path to the file.", Type: proto.ColumnType_STRING},
+		}),
+	}
+}
+
+type openAPIComponentExample struct {
+	Path string
+	Key  string
+	openapi3.Example
+}
+
+//// LIST FUNCTION
+
+func listOpenAPIComponentExamples(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	// The path comes from a parent hydrate, defaulting to the config paths or
+	// available by the optional key column
+	path := h.Item.(filePath).Path
+
+	// Get the indexed contents
+	idx, err := getDocIndex(ctx, d, path)
+	if err != nil {
+		plugin.Logger(ctx).Error("openapi_component_example.listOpenAPIComponentExamples", "parse_error", err)
+		return nil, err
+	}
+
+	// For each example, scan its arguments
+	for _, c := range idx.components("examples") {
+		v := c.Ref.(*openapi3.ExampleRef)
+		if v == nil || v.Value == nil {
+			continue
+		}
+		d.StreamListItem(ctx, openAPIComponentExample{path, c.Name, *v.Value})
+
+		// Context may get cancelled due to manual cancellation or if the limit has been reached
+		if d.RowsRemaining(ctx) == 0 {
+			return nil, nil
+		}
+	}
+
+	return nil, nil
+}
@@ -21,7 +21,7 @@ func tableOpenAPIComponentHeader(ctx context.Context) *plugin.Table {
 			Hydrate:       listOpenAPIComponentHeaders,
 			KeyColumns:    plugin.OptionalColumns([]string{"path"}),
 		},
-		Columns: []*plugin.Column{
+		Columns: commonColumns([]*plugin.Column{
 			{Name: "key", Description: "The key used to refer or search the header.", Type: proto.ColumnType_STRING},
 			{Name: "name", Description: "The name of the header.", Type: proto.ColumnType_STRING},
 			{Name: "location", Description: "The location of the header. Possible values are query, header, path or cookie.", Type: proto.ColumnType_STRING, Transform: transform.FromField("In").NullIfZero()},
@@ -35,7 +35,7 @@ func tableOpenAPIComponentHeader(ctx context.Context) *plugin.Table {
 			{Name: "schema", Description: "The schema of the header.", Type: proto.ColumnType_JSON, Transform: transform.FromField("Schema.Value")},
 			{Name: "schema_ref", Description: "The schema reference of the header.", Type: proto.ColumnType_STRING, Transform: transform.FromField("Schema.Ref").Transform(transform.NullIfZeroValue)},
 			{Name: "path", Description: "Path to the file.", Type: proto.ColumnType_STRING},
-		},
+		}),
 	}
 }
 
@@ -52,21 +52,20 @@ func listOpenAPIComponentHeaders(ctx context.Context, d *plugin.QueryData, h *pl
 	// available by the optional key column
 	path := h.Item.(filePath).Path
 
-	// Get the parsed contents
-	doc, err := getDoc(ctx, d, path)
+	// Get the indexed contents
+	idx, err := getDocIndex(ctx, d, path)
 	if err != nil {
 		plugin.Logger(ctx).Error("openapi_component_header.listOpenAPIComponentHeaders", "parse_error", err)
 		return nil, err
 	}
 
-	// Return nil, if no headers object defined
-	if doc.Components == nil || doc.Components.Headers == nil {
-		return nil, nil
-	}
-
 	// For each header, scan its arguments
-	for k, v := range doc.Components.Headers {
-		d.StreamListItem(ctx, openAPIComponentHeader{path, k, *v.Value})
+	for _, c := range idx.components("headers") {
+		v := c.Ref.(*openapi3.HeaderRef)
+		if v == nil || v.Value == nil {
+			continue
+		}
+		d.StreamListItem(ctx, openAPIComponentHeader{path, c.Name, *v.Value})
 
 		// Context may get cancelled due to manual cancellation or if the limit has been reached
 		if d.RowsRemaining(ctx) == 0 {
@@ -20,13 +20,13 @@ func tableOpenAPIComponentRequestBody(ctx context.Context) *plugin.Table {
 			Hydrate:       listOpenAPIComponentRequestBodies,
 			KeyColumns:    plugin.OptionalColumns([]string{"path"}),
 		},
-		Columns: []*plugin.Column{
+		Columns: commonColumns([]*plugin.Column{
 			{Name: "key", Description: "The key used to refer or search the request body.", Type: proto.ColumnType_STRING},
 			{Name: "description", Description: "A brief description of the request body.", Type: proto.ColumnType_STRING},
 			{Name: "required", Description: "True, if the request body is required.", Type: proto.ColumnType_BOOL},
 			{Name: "content", Description: "The content of the request body.", Type: proto.ColumnType_JSON},
 			{Name: "path", Description: "Path to the file.", Type: proto.ColumnType_STRING},
-		},
+		}),
 	}
 }
 
@@ -44,25 +44,26 @@ func listOpenAPIComponentRequestBodies(ctx context.Context, d *plugin.QueryData,
 	// available by the optional key column
 	path := h.Item.(filePath).Path
 
-	doc, err := getDoc(ctx, d, path)
+	// Get the indexed contents
+	idx, err := getDocIndex(ctx, d, path)
 	if err != nil {
 		plugin.Logger(ctx).Error("openapi_component_request_body.listOpenAPIComponentRequestBodies", "parse_error", err)
 		return nil, err
 	}
 
-	// Return nil, if no request bodies object defined
-	if doc.Components == nil || doc.Components.RequestBodies == nil {
-		return nil, nil
-	}
-
 	// For each request body, scan its arguments
-	for k, v := range doc.Components.RequestBodies {
+	for _, c := range idx.components("requestBodies") {
+		v := c.Ref.(*openapi3.RequestBodyRef)
+		if v == nil || v.Value == nil {
+			continue
+		}
 		requestBodyObject := openAPIComponentRequestBody{
 			Path: path,
-			Key:  k,
+			Key:  c.Name,
 		}
 
-		for header, content := range v.Value.Content {
+		for _, header := range sortedKeys(v.Value.Content) {
+			content := v.Value.Content[header]
 			requestBodyObject.Content = append(requestBodyObject.Content, map[string]interface{}{
 				"contentType": header,
 				"examples":    content.Examples,
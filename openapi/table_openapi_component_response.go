@@ -20,14 +20,14 @@ func tableOpenAPIComponentResponse(ctx context.Context) *plugin.Table {
 			Hydrate:       listOpenAPIComponentResponses,
 			KeyColumns:    plugin.OptionalColumns([]string{"path"}),
 		},
-		Columns: []*plugin.Column{
+		Columns: commonColumns([]*plugin.Column{
 			{Name: "key", Description: "The key of the response object definition.", Type: proto.ColumnType_STRING},
 			{Name: "description", Description: "A description of the response.", Type: proto.ColumnType_STRING},
 			{Name: "content", Description: "A map containing descriptions of potential response payloads.", Type: proto.ColumnType_JSON},
 			{Name: "headers", Description: "Maps a header name to its definition.", Type: proto.ColumnType_JSON, Transform: transform.FromField("Raw.Headers")},
 			{Name: "links", Description: "A map of operations links that can be followed from the response.", Type: proto.ColumnType_JSON, Transform: transform.FromField("Raw.Links")},
 			{Name: "path", Description: "Path to the file.", Type: proto.ColumnType_STRING},
-		},
+		}),
 	}
 }
 
@@ -46,27 +46,29 @@ func listOpenAPIComponentResponses(ctx context.Context, d *plugin.QueryData, h *
 	// available by the optional key column
 	path := h.Item.(filePath).Path
 
-	// Get the parsed contents
-	doc, err := getDoc(ctx, d, path)
+	// Get the indexed contents
+	idx, err := getDocIndex(ctx, d, path)
 	if err != nil {
 		plugin.Logger(ctx).Error("openapi_component_response.listOpenAPIComponentResponses", "parse_error", err)
 		return nil, err
 	}
 
-	// Return nil, if no responses object defined
-	if doc.Components == nil || doc.Components.Responses == nil {
-		return nil, nil
-	}
-
 	// For each response, scan its arguments
-	for k, v := range doc.Components.Responses {
+	for _, c := range idx.components("responses") {
+		v := c.Ref.(*openapi3.ResponseRef)
+		if v == nil || v.Value == nil {
+			continue
+		}
 		responseObject := openAPIComponentResponse{
-			Path:        path,
-			Key:         k,
-			Description: *v.Value.Description,
+			Path: path,
+			Key:  c.Name,
+		}
+		if v.Value.Description != nil {
+			responseObject.Description = *v.Value.Description
 		}
 
-		for header, content := range v.Value.Content {
+		for _, header := range sortedKeys(v.Value.Content) {
+			content := v.Value.Content[header]
 			responseObject.Content = append(responseObject.Content, map[string]interface{}{
 				"contentType": header,
 				"examples":    content.Examples,
@@ -21,7 +21,7 @@ func tableOpenAPIComponentSchema(ctx context.Context) *plugin.Table {
 			Hydrate:       listOpenAPIComponentSchemas,
 			KeyColumns:    plugin.OptionalColumns([]string{"path"}),
 		},
-		Columns: []*plugin.Column{
+		Columns: commonColumns([]*plugin.Column{
 			{Name: "name", Description: "The name of the property.", Type: proto.ColumnType_STRING},
 			{Name: "type", Description: "The type of the schema.", Type: proto.ColumnType_STRING},
 			{Name: "format", Description: "The format of a specific schema type.", Type: proto.ColumnType_STRING},
@@ -56,8 +56,15 @@ func tableOpenAPIComponentSchema(ctx context.Context) *plugin.Table {
 
 			{Name: "required", Description: "If true, the property must be defined.", Type: proto.ColumnType_JSON},
 			{Name: "properties", Description: "Describes the schema properties.", Type: proto.ColumnType_JSON},
+
+			// complexity metrics
+			{Name: "max_depth", Description: "The deepest nesting of properties, array items and map values in the schema and the schemas it references, each counted once.", Type: proto.ColumnType_INT, Hydrate: getOpenAPIComponentSchemaComplexity, Transform: transform.FromField("MaxDepth")},
+			{Name: "property_count", Description: "The number of properties of all objects in the schema and the schemas it references, each counted once.", Type: proto.ColumnType_INT, Hydrate: getOpenAPIComponentSchemaComplexity, Transform: transform.FromField("PropertyCount")},
+			{Name: "composition_branch_count", Description: "The number of allOf, oneOf and anyOf schemas in the schema and the schemas it references, each counted once.", Type: proto.ColumnType_INT, Hydrate: getOpenAPIComponentSchemaComplexity, Transform: transform.FromField("CompositionBranchCount")},
+			{Name: "ref_count", Description: "The number of references in the schema and the schemas it references, each counted once.", Type: proto.ColumnType_INT, Hydrate: getOpenAPIComponentSchemaComplexity, Transform: transform.FromField("RefCount")},
+			{Name: "fan_out_score", Description: "The number of scalar values in the schema, where each value counts once more for every array or map it is nested in.", Type: proto.ColumnType_INT, Hydrate: getOpenAPIComponentSchemaComplexity, Transform: transform.FromField("FanOutScore")},
 			{Name: "path", Description: "Path to the file.", Type: proto.ColumnType_STRING},
-		},
+		}),
 	}
 }
 
@@ -66,6 +73,8 @@ type openAPIComponentSchema struct {
 	Name string
 	openapi3.Schema
 	Properties map[string]interface{}
+	// SchemaRef is the declared schema, measured for the complexity metrics
+	SchemaRef *openapi3.SchemaRef
 }
 
 //// LIST FUNCTION
@@ -75,25 +84,20 @@ func listOpenAPIComponentSchemas(ctx context.Context, d *plugin.QueryData, h *pl
 	// available by the optional key column
 	path := h.Item.(filePath).Path
 
-	// Get the parsed contents
-	doc, err := getDoc(ctx, d, path)
+	// Get the indexed contents
+	idx, err := getDocIndex(ctx, d, path)
 	if err != nil {
 		plugin.Logger(ctx).Error("openapi_component_schema.listOpenAPIComponentSchemas", "parse_error", err)
 		return nil, err
 	}
 
-	// Return nil, if no schemas object defined
-	if doc.Components == nil || doc.Components.Schemas == nil {
-		return nil, nil
-	}
-
 	// For each schema, scan its arguments
-	for k, v := range doc.Components.Schemas {
+	for _, s := range idx.Schemas {
 		properties := map[string]interface{}{}
-		for i, j := range v.Value.Properties {
+		for i, j := range s.Schema.Value.Properties {
 			properties[i] = j.Value
 		}
-		d.StreamListItem(ctx, openAPIComponentSchema{path, k, *v.Value, properties})
+		d.StreamListItem(ctx, openAPIComponentSchema{path, s.Name, *s.Schema.Value, properties, s.Schema})
 
 		// Context may get cancelled due to manual cancellation or if the limit has been reached
 		if d.RowsRemaining(ctx) == 0 {
@@ -103,3 +107,12 @@ func listOpenAPIComponentSchemas(ctx context.Context, d *plugin.QueryData, h *pl
 
 	return nil, nil
 }
+
+//// HYDRATE FUNCTIONS
+
+func getOpenAPIComponentSchemaComplexity(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	schema := h.Item.(openAPIComponentSchema)
+	// The declared schema is measured, rather than the copy in the row, so
+	// that references to itself are recognized as recursive
+	return measureSchema(schema.SchemaRef), nil
+}
@@ -21,7 +21,7 @@ func tableOpenAPIComponentSecurityScheme(ctx context.Context) *plugin.Table {
 			Hydrate:       listOpenAPIComponentSecuritySchemes,
 			KeyColumns:    plugin.OptionalColumns([]string{"path"}),
 		},
-		Columns: []*plugin.Column{
+		Columns: commonColumns([]*plugin.Column{
 			{Name: "key", Description: "The key used to refer or search the security scheme.", Type: proto.ColumnType_STRING},
 			{Name: "name", Description: "The name of the header, query or cookie parameter to be used.", Type: proto.ColumnType_STRING},
 			{Name: "type", Description: "The type of the security scheme. Valid values are apiKey, http, mutualTLS, oauth2, openIdConnect.", Type: proto.ColumnType_STRING},
@@ -32,7 +32,7 @@ func tableOpenAPIComponentSecurityScheme(ctx context.Context) *plugin.Table {
 			{Name: "open_id_connect_url", Description: "OpenId Connect URL to discover OAuth2 configuration values.", Type: proto.ColumnType_STRING},
 			{Name: "flows", Description: "An object containing configuration information for the flow types supported.", Type: proto.ColumnType_JSON},
 			{Name: "path", Description: "Path to the file.", Type: proto.ColumnType_STRING},
-		},
+		}),
 	}
 }
 
@@ -49,21 +49,20 @@ func listOpenAPIComponentSecuritySchemes(ctx context.Context, d *plugin.QueryDat
 	// available by the optional key column
 	path := h.Item.(filePath).Path
 
-	// Get the parsed contents
-	doc, err := getDoc(ctx, d, path)
+	// Get the indexed contents
+	idx, err := getDocIndex(ctx, d, path)
 	if err != nil {
 		plugin.Logger(ctx).Error("openapi_component_security_scheme.listOpenAPIComponentSecuritySchemes", "parse_error", err)
 		return nil, err
 	}
 
-	// Return nil, if no security schemes object defined
-	if doc.Components == nil || doc.Components.SecuritySchemes == nil {
-		return nil, nil
-	}
-
 	// For each security scheme, scan its arguments
-	for k, v := range doc.Components.SecuritySchemes {
-		d.StreamListItem(ctx, openAPIComponentSecurityScheme{path, k, *v.Value})
+	for _, c := range idx.components("securitySchemes") {
+		v := c.Ref.(*openapi3.SecuritySchemeRef)
+		if v == nil || v.Value == nil {
+			continue
+		}
+		d.StreamListItem(ctx, openAPIComponentSecurityScheme{path, c.Name, *v.Value})
 
 		// Context may get cancelled due to manual cancellation or if the limit has been reached
 		if d.RowsRemaining(ctx) == 0 {
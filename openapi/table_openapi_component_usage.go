@@ -0,0 +1,245 @@
+package openapi
+
+import (
+	"context"
+	p "path"
+	"strings"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+//// TABLE DEFINITION
+
+func tableOpenAPIComponentUsage(ctx context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "openapi_component_usage",
+		Description: "How often each component is referenced, and which operations use it.",
+		List: &plugin.ListConfig{
+			ParentHydrate: listOpenAPIFiles,
+			Hydrate:       listOpenAPIComponentUsages,
+			KeyColumns:    plugin.OptionalColumns([]string{"path"}),
+		},
+		Columns: commonColumns([]*plugin.Column{
+			{Name: "name", Description: "The name of the component.", Type: proto.ColumnType_STRING},
+			{Name: "kind", Description: "The kind of component. Possible values are schema, parameter, header, requestBody, response, securityScheme, example, link and callback.", Type: proto.ColumnType_STRING},
+			{Name: "pointer", Description: "The JSON pointer to the component in the document.", Type: proto.ColumnType_STRING},
+			{Name: "reference_count", Description: "The number of references to the component anywhere in the document. For security schemes, this is the number of security requirements using the scheme.", Type: proto.ColumnType_INT},
+			{Name: "direct_operation_count", Description: "The number of operations referencing the component directly.", Type: proto.ColumnType_INT},
+			{Name: "operation_count", Description: "The number of operations using the component, either directly or through other components.", Type: proto.ColumnType_INT},
+			{Name: "operations", Description: "The operations using the component, either directly or through other components.", Type: proto.ColumnType_JSON},
+			{Name: "path", Description: "Path to the file.", Type: proto.ColumnType_STRING},
+		}),
+	}
+}
+
+type openAPIComponentUsage struct {
+	Path                 string
+	Name                 string
+	Kind                 string
+	Pointer              string
+	ReferenceCount       int
+	DirectOperationCount int
+	OperationCount       int
+	Operations           []componentUsageOperation
+}
+
+// componentUsageOperation identifies an operation in the same form as the
+// openapi_path table
+type componentUsageOperation struct {
+	ApiPath string `json:"api_path"`
+	Method  string `json:"method"`
+}
+
+// componentSections maps the sections of components to the kind of object
+// they hold, in the order the components are listed
+var componentSections = []struct {
+	Section string
+	Kind    string
+}{
+	{"schemas", "schema"},
+	{"parameters", "parameter"},
+	{"headers", "header"},
+	{"requestBodies", "requestBody"},
+	{"responses", "response"},
+	{"securitySchemes", "securityScheme"},
+	{"examples", "example"},
+	{"links", "link"},
+	{"callbacks", "callback"},
+}
+
+//// LIST FUNCTION
+
+func listOpenAPIComponentUsages(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	// The path comes from a parent hydrate, defaulting to the config paths or
+	// available by the optional key column
+	path := h.Item.(filePath).Path
+
+	// Get the indexed contents
+	idx, err := getDocIndex(ctx, d, path)
+	if err != nil {
+		plugin.Logger(ctx).Error("openapi_component_usage.listOpenAPIComponentUsages", "parse_error", err)
+		return nil, err
+	}
+
+	usage := buildComponentUsage(idx)
+
+	for _, section := range componentSections {
+		for _, c := range idx.components(section.Section) {
+			ptr := c.Pointer
+			row := openAPIComponentUsage{
+				Path:                 path,
+				Name:                 c.Name,
+				Kind:                 section.Kind,
+				Pointer:              ptr,
+				ReferenceCount:       usage.references[ptr],
+				DirectOperationCount: len(usage.direct[ptr]),
+				Operations:           []componentUsageOperation{},
+			}
+			for _, op := range idx.Operations {
+				if usage.reachable[op.Pointer][ptr] {
+					row.Operations = append(row.Operations, componentUsageOperation{
+						ApiPath: p.Join(op.ApiPath, op.Method),
+						Method:  strings.ToUpper(op.Method),
+					})
+				}
+			}
+			row.OperationCount = len(row.Operations)
+			d.StreamListItem(ctx, row)
+
+			// Context may get cancelled due to manual cancellation or if the limit has been reached
+			if d.RowsRemaining(ctx) == 0 {
+				return nil, nil
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+// componentUsage is the reference graph between operations and components.
+// Components are identified by their JSON pointer, e.g.
+// /components/schemas/Pet, and operations by the pointer of the operation.
+type componentUsage struct {
+	// references counts the references to each component
+	references map[string]int
+	// edges holds the components each component references directly
+	edges map[string]map[string]bool
+	// direct holds the operations referencing each component directly
+	direct map[string]map[string]bool
+	// reachable holds every component each operation uses
+	reachable map[string]map[string]bool
+}
+
+func buildComponentUsage(idx *docIndex) *componentUsage {
+	u := &componentUsage{
+		references: map[string]int{},
+		edges:      map[string]map[string]bool{},
+		direct:     map[string]map[string]bool{},
+		reachable:  map[string]map[string]bool{},
+	}
+
+	// Operations declared under each path item, so that references from path
+	// level parameters apply to all of them
+	operations := map[string][]string{}
+	for _, op := range idx.Operations {
+		item := pointerJoin("/paths", op.ApiPath)
+		operations[item] = append(operations[item], op.Pointer)
+	}
+
+	// Discriminator mappings and link operationRefs reference components as
+	// a $ref does, e.g. a schema only used as a mapping value is still used
+	refs := append(append([]*indexedRef{}, idx.Refs...), idx.MappingRefs...)
+	for _, ref := range refs {
+		if !strings.HasPrefix(ref.Ref, "#/components/") {
+			continue
+		}
+		target := componentPointer(ref.Ref[1:])
+		if target == "" {
+			continue
+		}
+		u.references[target]++
+
+		if source := componentPointer(ref.Pointer); source != "" {
+			addUsageEdge(u.edges, source, target)
+			continue
+		}
+		tokens := strings.SplitN(ref.Pointer, "/", 5)
+		if len(tokens) < 4 || tokens[1] != "paths" {
+			continue
+		}
+		item := "/" + tokens[1] + "/" + tokens[2]
+		if tokens[3] == "parameters" {
+			for _, op := range operations[item] {
+				addUsageEdge(u.direct, target, op)
+			}
+			continue
+		}
+		addUsageEdge(u.direct, target, item+"/"+tokens[3])
+	}
+
+	// Security schemes are used by name in security requirements rather than
+	// by reference. Operations without requirements of their own use the
+	// requirements of the document.
+	for _, requirements := range idx.Doc.Security {
+		for name := range requirements {
+			u.references[pointerJoin("/components/securitySchemes", name)]++
+		}
+	}
+	for _, op := range idx.Operations {
+		security := idx.Doc.Security
+		if op.Operation.Security != nil {
+			security = *op.Operation.Security
+			for _, requirements := range security {
+				for name := range requirements {
+					u.references[pointerJoin("/components/securitySchemes", name)]++
+				}
+			}
+		}
+		for _, requirements := range security {
+			for name := range requirements {
+				addUsageEdge(u.direct, pointerJoin("/components/securitySchemes", name), op.Pointer)
+			}
+		}
+	}
+
+	// Walk the graph from the components each operation uses directly
+	for target, ops := range u.direct {
+		for op := range ops {
+			if u.reachable[op] == nil {
+				u.reachable[op] = map[string]bool{}
+			}
+			u.visit(u.reachable[op], target)
+		}
+	}
+
+	return u
+}
+
+func (u *componentUsage) visit(seen map[string]bool, ptr string) {
+	if seen[ptr] {
+		return
+	}
+	seen[ptr] = true
+	for next := range u.edges[ptr] {
+		u.visit(seen, next)
+	}
+}
+
+func addUsageEdge(edges map[string]map[string]bool, from string, to string) {
+	if edges[from] == nil {
+		edges[from] = map[string]bool{}
+	}
+	edges[from][to] = true
+}
+
+// componentPointer returns the pointer of the component containing ptr, e.g.
+// /components/schemas/Pet for /components/schemas/Pet/properties/id, or an
+// empty string if ptr is not inside a component.
+func componentPointer(ptr string) string {
+	tokens := strings.SplitN(ptr, "/", 5)
+	if len(tokens) < 4 || tokens[0] != "" || tokens[1] != "components" || tokens[3] == "" {
+		return ""
+	}
+	return strings.Join(tokens[:4], "/")
+}
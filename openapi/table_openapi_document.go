@@ -0,0 +1,236 @@
+package openapi
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	p "path"
+	"strings"
+
+	"github.com/invopop/yaml"
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/memoize"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+//// TABLE DEFINITION
+
+func tableOpenAPIDocument(ctx context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "openapi_document",
+		Description: "The bundled and dereferenced contents of each definition file, and the files skipped.",
+		List: &plugin.ListConfig{
+			ParentHydrate: listOpenAPIFilesWithSkipped,
+			Hydrate:       listOpenAPIDocuments,
+			KeyColumns:    plugin.OptionalColumns([]string{"path"}),
+		},
+		Columns: commonColumns([]*plugin.Column{
+			{Name: "skipped_reason", Description: "The reason the file was skipped rather than loaded, e.g. it is larger than max_load_memory_mb. Null for files that are loaded.", Type: proto.ColumnType_STRING, Transform: transform.FromField("SkippedReason").NullIfZero()},
+			{Name: "content_hash", Description: "The SHA-256 hash of the bundled document, as canonical JSON with sorted keys.", Type: proto.ColumnType_STRING, Transform: transform.FromField("ContentHash").NullIfZero()},
+			{Name: "bundled", Description: "The document with every external reference copied into components, so it is self-contained.", Type: proto.ColumnType_JSON},
+			{Name: "bundled_yaml", Description: "The bundled document as YAML.", Type: proto.ColumnType_STRING, Hydrate: getOpenAPIDocumentYAML, Transform: transform.FromField("BundledYAML").NullIfZero()},
+			{Name: "dereferenced", Description: "The bundled document with every reference replaced by its target. Recursive references are kept as $ref objects.", Type: proto.ColumnType_JSON, Hydrate: getOpenAPIDocumentDereferenced, Transform: transform.FromField("Dereferenced")},
+			{Name: "dereferenced_yaml", Description: "The dereferenced document as YAML.", Type: proto.ColumnType_STRING, Hydrate: getOpenAPIDocumentDereferenced, Transform: transform.FromField("DereferencedYAML").NullIfZero()},
+			{Name: "path", Description: "Path to the file.", Type: proto.ColumnType_STRING},
+		}),
+	}
+}
+
+type openAPIDocument struct {
+	Path          string
+	ContentHash   string
+	Bundled       interface{}
+	SkippedReason string
+}
+
+type openAPIDocumentYAML struct {
+	BundledYAML string
+}
+
+type openAPIDocumentDereferenced struct {
+	Dereferenced     interface{}
+	DereferencedYAML string
+}
+
+//// LIST FUNCTION
+
+func listOpenAPIDocuments(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	// The path comes from a parent hydrate, defaulting to the config paths or
+	// available by the optional key column
+	file := h.Item.(filePath)
+	path := file.Path
+
+	// Skipped files are listed without content
+	if file.SkippedReason != "" {
+		d.StreamListItem(ctx, openAPIDocument{Path: path, SkippedReason: file.SkippedReason})
+		return nil, nil
+	}
+
+	bundled, err := getBundledDoc(ctx, d, path)
+	if err != nil {
+		plugin.Logger(ctx).Error("openapi_document.listOpenAPIDocuments", "parse_error", err)
+		return nil, err
+	}
+
+	// Marshalling generic JSON values sorts object keys, so the hash only
+	// changes when the content does
+	data, err := json.Marshal(bundled)
+	if err != nil {
+		plugin.Logger(ctx).Error("openapi_document.listOpenAPIDocuments", "marshal_error", err)
+		return nil, err
+	}
+	sum := sha256.Sum256(data)
+
+	d.StreamListItem(ctx, openAPIDocument{Path: path, ContentHash: hex.EncodeToString(sum[:]), Bundled: bundled})
+
+	return nil, nil
+}
+
+//// HYDRATE FUNCTIONS
+
+func getOpenAPIDocumentYAML(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	doc := h.Item.(openAPIDocument)
+	if doc.SkippedReason != "" {
+		return openAPIDocumentYAML{}, nil
+	}
+
+	content, err := jsonValueToYAML(doc.Bundled)
+	if err != nil {
+		plugin.Logger(ctx).Error("openapi_document.getOpenAPIDocumentYAML", "marshal_error", err, "path", doc.Path)
+		return nil, err
+	}
+
+	return openAPIDocumentYAML{content}, nil
+}
+
+func getOpenAPIDocumentDereferenced(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	doc := h.Item.(openAPIDocument)
+	if doc.SkippedReason != "" {
+		return openAPIDocumentDereferenced{}, nil
+	}
+
+	// Once bundled, every reference is local
+	dereferenced := inlineLocalRefs(doc.Bundled, doc.Bundled)
+	content, err := jsonValueToYAML(dereferenced)
+	if err != nil {
+		plugin.Logger(ctx).Error("openapi_document.getOpenAPIDocumentDereferenced", "marshal_error", err, "path", doc.Path)
+		return nil, err
+	}
+
+	return openAPIDocumentDereferenced{dereferenced, content}, nil
+}
+
+// getBundledDoc returns the bundled form of the document at path. The result
+// is shared, so must not be modified.
+func getBundledDoc(ctx context.Context, d *plugin.QueryData, path string) (interface{}, error) {
+	h := &plugin.HydrateData{Item: path}
+	return getBundledDocCached(ctx, d, h)
+}
+
+// Cached form of getBundledDoc, see getDocCached.
+var getBundledDocCached = plugin.HydrateFunc(getBundledDocUncached).Memoize(memoize.WithCacheKeyFunction(getBundledDocCacheKey))
+
+func getBundledDocCacheKey(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	path := h.Item.(string)
+	key := fmt.Sprintf("getBundledDoc-%s", path)
+	return key, nil
+}
+
+// getBundledDocUncached bundles the document at path. Do not call this
+// directly, use getBundledDoc instead.
+func getBundledDocUncached(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	return bundleDoc(ctx, d, h.Item.(string))
+}
+
+// bundleDoc returns the document at path as generic JSON values, with every
+// external reference copied into components and replaced by a local
+// reference. External references are only resolved if allow_external_refs
+// is set, otherwise a document with any fails to load. Internalizing
+// references modifies the document, so a fresh copy is loaded rather than
+// changing the one shared by other tables.
+func bundleDoc(ctx context.Context, d *plugin.QueryData, path string) (interface{}, error) {
+	config := GetConfig(d.Connection)
+	loaded, err := loadDocument(ctx, d, path, config.AllowExternalRefs != nil && *config.AllowExternalRefs)
+	if err != nil {
+		return nil, err
+	}
+	doc := loaded.Doc
+	doc.InternalizeRefs(ctx, newBundleRefNameResolver(doc))
+
+	bundled, err := toJSONValue(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to bundle file %s: %v", path, err)
+	}
+	return bundled, nil
+}
+
+// newBundleRefNameResolver returns a function naming the component each
+// external reference of doc is copied to. Names default to the last token of
+// the reference, e.g. Pet for pets.yaml#/Pet. A name already declared in doc,
+// or given to another reference, is prefixed with the stem of the referenced
+// file, e.g. b_Pet for b.yaml#/Pet, then suffixed with a number until unique.
+func newBundleRefNameResolver(doc *openapi3.T) func(string) string {
+	idx := buildDocIndex(doc)
+	used := map[string]bool{}
+	for _, c := range idx.Components {
+		used[c.Name] = true
+	}
+
+	// Components which are themselves external references keep their name
+	refs := map[string]string{}
+	for _, r := range idx.Refs {
+		refs[r.Pointer] = r.Ref
+	}
+	names := map[string]string{}
+	for _, c := range idx.Components {
+		if ref := refs[c.Pointer]; ref != "" && !strings.HasPrefix(ref, "#") {
+			names[ref] = c.Name
+		}
+	}
+
+	return func(ref string) string {
+		if name, ok := names[ref]; ok {
+			return name
+		}
+		name := bundleComponentName(openapi3.DefaultRefNameResolver(ref))
+		if used[name] {
+			file, _, _ := strings.Cut(ref, "#")
+			if stem := strings.TrimSuffix(p.Base(file), p.Ext(file)); file != "" && stem != "" {
+				name = bundleComponentName(stem + "_" + name)
+			}
+		}
+		for base, i := name, 2; used[name]; i++ {
+			name = fmt.Sprintf("%s%d", base, i)
+		}
+		used[name] = true
+		names[ref] = name
+		return name
+	}
+}
+
+// bundleComponentName replaces the characters not allowed in component names
+func bundleComponentName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '.' || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+func jsonValueToYAML(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	content, err := yaml.JSONToYAML(data)
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
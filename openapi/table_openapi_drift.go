@@ -0,0 +1,197 @@
+package openapi
+
+import (
+	"context"
+	p "path"
+	"reflect"
+	"strings"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+//// TABLE DEFINITION
+
+func tableOpenAPIDrift(ctx context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "openapi_drift",
+		Description: "Differences between a local definition file and the definition served by a running service.",
+		List: &plugin.ListConfig{
+			Hydrate:    listOpenAPIDrift,
+			KeyColumns: plugin.AllColumns([]string{"path", "served_url"}),
+		},
+		Columns: commonColumns([]*plugin.Column{
+			{Name: "kind", Description: "The kind of object that differs. Possible values are operation, parameter and schema.", Type: proto.ColumnType_STRING},
+			{Name: "change", Description: "How the object differs. Possible values are only_local, only_served and changed.", Type: proto.ColumnType_STRING},
+			{Name: "name", Description: "The name of the parameter or schema.", Type: proto.ColumnType_STRING},
+			{Name: "location", Description: "The location of the parameter. Possible values are query, header, path or cookie.", Type: proto.ColumnType_STRING},
+			{Name: "api_path", Description: "The API path of the operation or parameter, in the same form as the openapi_path table.", Type: proto.ColumnType_STRING},
+			{Name: "method", Description: "The HTTP method of the operation or parameter.", Type: proto.ColumnType_STRING},
+			{Name: "local_value", Description: "The object in the local definition, with local references inlined.", Type: proto.ColumnType_JSON},
+			{Name: "served_value", Description: "The object in the served definition, with local references inlined.", Type: proto.ColumnType_JSON},
+			{Name: "served_url", Description: "The URL of the served definition.", Type: proto.ColumnType_STRING},
+			{Name: "path", Description: "Path to the local file.", Type: proto.ColumnType_STRING},
+		}),
+	}
+}
+
+type openAPIDrift struct {
+	Path        string
+	ServedURL   string
+	Kind        string
+	Change      string
+	Name        string
+	Location    string
+	ApiPath     string
+	Method      string
+	LocalValue  interface{}
+	ServedValue interface{}
+}
+
+// driftObject is an object compared between the two definitions, with the
+// fields identifying it in a row
+type driftObject struct {
+	Kind     string
+	Name     string
+	Location string
+	ApiPath  string
+	Method   string
+	Value    interface{}
+}
+
+//// LIST FUNCTION
+
+func listOpenAPIDrift(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	path := d.EqualsQualString("path")
+	servedURL := d.EqualsQualString("served_url")
+
+	local, err := driftObjects(ctx, d, path)
+	if err != nil {
+		plugin.Logger(ctx).Error("openapi_drift.listOpenAPIDrift", "parse_error", err, "path", path)
+		return nil, err
+	}
+	served, err := driftObjects(ctx, d, servedURL)
+	if err != nil {
+		plugin.Logger(ctx).Error("openapi_drift.listOpenAPIDrift", "parse_error", err, "served_url", servedURL)
+		return nil, err
+	}
+
+	keys := map[string]bool{}
+	for k := range local {
+		keys[k] = true
+	}
+	for k := range served {
+		keys[k] = true
+	}
+
+	for _, k := range sortedKeys(keys) {
+		l, s := local[k], served[k]
+
+		var change string
+		var obj *driftObject
+		switch {
+		case s == nil:
+			change, obj = "only_local", l
+		case l == nil:
+			change, obj = "only_served", s
+		case !reflect.DeepEqual(l.Value, s.Value):
+			change, obj = "changed", l
+		default:
+			continue
+		}
+
+		row := openAPIDrift{
+			Path:      path,
+			ServedURL: servedURL,
+			Kind:      obj.Kind,
+			Change:    change,
+			Name:      obj.Name,
+			Location:  obj.Location,
+			ApiPath:   obj.ApiPath,
+			Method:    obj.Method,
+		}
+		if l != nil {
+			row.LocalValue = l.Value
+		}
+		if s != nil {
+			row.ServedValue = s.Value
+		}
+		d.StreamListItem(ctx, row)
+
+		// Context may get cancelled due to manual cancellation or if the limit has been reached
+		if d.RowsRemaining(ctx) == 0 {
+			return nil, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// driftObjects returns the operations, effective operation parameters and
+// component schemas of the document at path, keyed by their identity.
+// Values have local references inlined, so a definition using a $ref and one
+// declaring the same object inline are treated as equal.
+func driftObjects(ctx context.Context, d *plugin.QueryData, path string) (map[string]*driftObject, error) {
+	idx, err := getDocIndex(ctx, d, path)
+	if err != nil {
+		return nil, err
+	}
+	root, err := toJSONValue(idx.Doc)
+	if err != nil {
+		return nil, err
+	}
+
+	objects := map[string]*driftObject{}
+	for _, op := range idx.Operations {
+		apiPath := p.Join(op.ApiPath, op.Method)
+		method := strings.ToUpper(op.Method)
+
+		value, _ := resolvePointer(root, op.Pointer)
+		objects["operation "+method+" "+op.ApiPath] = &driftObject{
+			Kind:    "operation",
+			ApiPath: apiPath,
+			Method:  method,
+			Value:   inlineLocalRefs(root, value),
+		}
+
+		// Operation parameters override path item parameters with the same
+		// name and location
+		parameters := map[string]*driftObject{}
+		for _, param := range idx.Parameters {
+			if param.ApiPath != op.ApiPath || (param.Method != "" && param.Method != op.Method) {
+				continue
+			}
+			value, _ := resolvePointer(root, param.Pointer)
+			value = inlineLocalRefs(root, value)
+			m, _ := value.(map[string]interface{})
+			name, _ := m["name"].(string)
+			in, _ := m["in"].(string)
+			key := "parameter " + method + " " + op.ApiPath + " " + in + " " + name
+			if _, ok := parameters[key]; ok && param.Method == "" {
+				continue
+			}
+			parameters[key] = &driftObject{
+				Kind:     "parameter",
+				Name:     name,
+				Location: in,
+				ApiPath:  apiPath,
+				Method:   method,
+				Value:    value,
+			}
+		}
+		for k, v := range parameters {
+			objects[k] = v
+		}
+	}
+
+	for _, s := range idx.Schemas {
+		value, _ := resolvePointer(root, s.Pointer)
+		objects["schema "+s.Name] = &driftObject{
+			Kind:  "schema",
+			Name:  s.Name,
+			Value: inlineLocalRefs(root, value),
+		}
+	}
+
+	return objects, nil
+}
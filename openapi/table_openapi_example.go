@@ -0,0 +1,87 @@
+package openapi
+
+import (
+	"context"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+//// TABLE DEFINITION
+
+func tableOpenAPIExample(ctx context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "openapi_example",
+		Description: "Every example in each definition file.",
+		List: &plugin.ListConfig{
+			ParentHydrate: listOpenAPIFiles,
+			Hydrate:       listOpenAPIExamples,
+			KeyColumns:    plugin.OptionalColumns([]string{"path"}),
+		},
+		Columns: commonColumns([]*plugin.Column{
+			{Name: "pointer", Description: "The JSON pointer to the example in the document.", Type: proto.ColumnType_STRING},
+			{Name: "location", Description: "Where the example is declared. Possible values are component, media_type, parameter, header and schema.", Type: proto.ColumnType_STRING},
+			{Name: "name", Description: "The key of the example in an examples map. Null for an example field.", Type: proto.ColumnType_STRING, Transform: transform.FromField("Name").NullIfZero()},
+			{Name: "summary", Description: "A short summary of the example.", Type: proto.ColumnType_STRING, Transform: transform.FromField("Summary").NullIfZero()},
+			{Name: "description", Description: "A long description of the example.", Type: proto.ColumnType_STRING, Transform: transform.FromField("Description").NullIfZero()},
+			{Name: "value", Description: "The example value. For references to component examples, this is the value of the component example.", Type: proto.ColumnType_JSON},
+			{Name: "external_value", Description: "A URL that points to the literal example.", Type: proto.ColumnType_STRING, Transform: transform.FromField("ExternalValue").NullIfZero()},
+			{Name: "ref", Description: "The reference to the component example, if the example is a reference.", Type: proto.ColumnType_STRING, Transform: transform.FromField("Ref").NullIfZero()},
+			{Name: "path", Description: "Path to the file.", Type: proto.ColumnType_STRING},
+		}),
+	}
+}
+
+type openAPIExample struct {
+	Path          string
+	Pointer       string
+	Location      string
+	Name          string
+	Summary       string
+	Description   string
+	Value         interface{}
+	ExternalValue string
+	Ref           string
+}
+
+//// LIST FUNCTION
+
+func listOpenAPIExamples(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	// The path comes from a parent hydrate, defaulting to the config paths or
+	// available by the optional key column
+	path := h.Item.(filePath).Path
+
+	// Get the indexed contents
+	idx, err := getDocIndex(ctx, d, path)
+	if err != nil {
+		plugin.Logger(ctx).Error("openapi_example.listOpenAPIExamples", "parse_error", err)
+		return nil, err
+	}
+
+	for _, e := range idx.Examples {
+		row := openAPIExample{
+			Path:     path,
+			Pointer:  e.Pointer,
+			Location: e.Location,
+			Name:     e.Name,
+			Value:    e.Value,
+		}
+		if e.Example != nil {
+			row.Ref = e.Example.Ref
+			if v := e.Example.Value; v != nil {
+				row.Summary = v.Summary
+				row.Description = v.Description
+				row.ExternalValue = v.ExternalValue
+			}
+		}
+		d.StreamListItem(ctx, row)
+
+		// Context may get cancelled due to manual cancellation or if the limit has been reached
+		if d.RowsRemaining(ctx) == 0 {
+			return nil, nil
+		}
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,146 @@
+package openapi
+
+import (
+	"context"
+	"errors"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+//// TABLE DEFINITION
+
+func tableOpenAPIExampleValidation(ctx context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "openapi_example_validation",
+		Description: "The result of validating each example against the schema it is for.",
+		List: &plugin.ListConfig{
+			ParentHydrate: listOpenAPIFiles,
+			Hydrate:       listOpenAPIExampleValidations,
+			KeyColumns:    plugin.OptionalColumns([]string{"path"}),
+		},
+		Columns: commonColumns([]*plugin.Column{
+			{Name: "pointer", Description: "The JSON pointer to the example in the document.", Type: proto.ColumnType_STRING},
+			{Name: "location", Description: "Where the example is declared. Possible values are media_type, parameter, header and schema.", Type: proto.ColumnType_STRING},
+			{Name: "name", Description: "The key of the example in an examples map. Null for an example field.", Type: proto.ColumnType_STRING, Transform: transform.FromField("Name").NullIfZero()},
+			{Name: "schema_ref", Description: "The reference to the component schema the example is validated against, if the schema is a reference.", Type: proto.ColumnType_STRING, Transform: transform.FromField("SchemaRef").NullIfZero()},
+			{Name: "valid", Description: "True if the example is valid against the schema.", Type: proto.ColumnType_BOOL},
+			{Name: "error", Description: "The validation error, if the example is not valid.", Type: proto.ColumnType_STRING, Transform: transform.FromField("Error").NullIfZero()},
+			{Name: "errors", Description: "Each validation error, with the JSON pointer to the invalid value within the example.", Type: proto.ColumnType_JSON},
+			{Name: "value", Description: "The example value.", Type: proto.ColumnType_JSON},
+			{Name: "path", Description: "Path to the file.", Type: proto.ColumnType_STRING},
+		}),
+	}
+}
+
+type openAPIExampleValidation struct {
+	Path      string
+	Pointer   string
+	Location  string
+	Name      string
+	SchemaRef string
+	Valid     bool
+	Error     string
+	Errors    []exampleValidationError
+	Value     interface{}
+}
+
+type exampleValidationError struct {
+	Pointer string `json:"pointer"`
+	Reason  string `json:"reason"`
+}
+
+//// LIST FUNCTION
+
+func listOpenAPIExampleValidations(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	// The path comes from a parent hydrate, defaulting to the config paths or
+	// available by the optional key column
+	path := h.Item.(filePath).Path
+
+	// Get the indexed contents
+	idx, err := getDocIndex(ctx, d, path)
+	if err != nil {
+		plugin.Logger(ctx).Error("openapi_example_validation.listOpenAPIExampleValidations", "parse_error", err)
+		return nil, err
+	}
+
+	// Component examples are not for a schema of their own, they are
+	// validated wherever they are referenced
+	for _, e := range idx.Examples {
+		if e.Schema == nil || e.Schema.Value == nil || e.Value == nil {
+			continue
+		}
+		row := openAPIExampleValidation{
+			Path:      path,
+			Pointer:   e.Pointer,
+			Location:  e.Location,
+			Name:      e.Name,
+			SchemaRef: e.Schema.Ref,
+			Valid:     true,
+			Errors:    []exampleValidationError{},
+			Value:     e.Value,
+		}
+		if err := validateExample(e); err != nil {
+			row.Valid = false
+			row.Error = err.Error()
+			row.Errors = exampleValidationErrors(err)
+		}
+		d.StreamListItem(ctx, row)
+
+		// Context may get cancelled due to manual cancellation or if the limit has been reached
+		if d.RowsRemaining(ctx) == 0 {
+			return nil, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// validateExample validates an example against its schema. Examples in a
+// request body may omit readOnly properties, and those in a response may
+// omit writeOnly properties.
+func validateExample(e *indexedExample) error {
+	// Values parsed from YAML may hold integer types, while validation
+	// expects values as decoded from JSON
+	value, err := toJSONValue(e.Value)
+	if err != nil {
+		return err
+	}
+
+	opts := []openapi3.SchemaValidationOption{openapi3.MultiErrors(), openapi3.EnableFormatValidation()}
+	switch e.Context {
+	case "request":
+		opts = append(opts, openapi3.VisitAsRequest())
+	case "response":
+		opts = append(opts, openapi3.VisitAsResponse())
+	}
+	return e.Schema.Value.VisitJSON(value, opts...)
+}
+
+// exampleValidationErrors splits a validation error into the errors for each
+// invalid value
+func exampleValidationErrors(err error) []exampleValidationError {
+	var errs []error
+	var multi openapi3.MultiError
+	if errors.As(err, &multi) {
+		errs = multi
+	} else {
+		errs = []error{err}
+	}
+
+	var result []exampleValidationError
+	for _, e := range errs {
+		var schemaErr *openapi3.SchemaError
+		if errors.As(e, &schemaErr) {
+			result = append(result, exampleValidationError{
+				Pointer: pointerJoin("", schemaErr.JSONPointer()...),
+				Reason:  schemaErr.Reason,
+			})
+			continue
+		}
+		result = append(result, exampleValidationError{Reason: e.Error()})
+	}
+	return result
+}
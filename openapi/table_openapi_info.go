@@ -20,7 +20,7 @@ func tableOpenAPIInfo(ctx context.Context) *plugin.Table {
 			Hydrate:       listOpenAPIInfo,
 			KeyColumns:    plugin.OptionalColumns([]string{"path"}),
 		},
-		Columns: []*plugin.Column{
+		Columns: commonColumns([]*plugin.Column{
 			{Name: "title", Description: "The title of the API.", Type: proto.ColumnType_STRING},
 			{Name: "description", Description: "A description of the API.", Type: proto.ColumnType_STRING},
 			{Name: "version", Description: "The version of the OpenAPI document.", Type: proto.ColumnType_STRING},
@@ -29,7 +29,7 @@ func tableOpenAPIInfo(ctx context.Context) *plugin.Table {
 			{Name: "license", Description: "The license information for the exposed API.", Type: proto.ColumnType_JSON},
 			{Name: "specification_version", Description: "The version of the OpenAPI specification.", Type: proto.ColumnType_STRING},
 			{Name: "path", Description: "Path to the file.", Type: proto.ColumnType_STRING},
-		},
+		}),
 	}
 }
 
@@ -0,0 +1,63 @@
+package openapi
+
+import (
+	"context"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+//// TABLE DEFINITION
+
+func tableOpenAPIOverlayAction(ctx context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "openapi_overlay_action",
+		Description: "Actions of the overlays applied to each definition file.",
+		List: &plugin.ListConfig{
+			ParentHydrate: listOpenAPIFiles,
+			Hydrate:       listOpenAPIOverlayActions,
+			KeyColumns:    plugin.OptionalColumns([]string{"path"}),
+		},
+		Columns: commonColumns([]*plugin.Column{
+			{Name: "overlay_path", Description: "Path to the overlay file.", Type: proto.ColumnType_STRING},
+			{Name: "action_index", Description: "The position of the action in the overlay actions list, starting at 0.", Type: proto.ColumnType_INT},
+			{Name: "target", Description: "The JSONPath expression selecting the objects the action applies to.", Type: proto.ColumnType_STRING},
+			{Name: "description", Description: "A description of the action.", Type: proto.ColumnType_STRING},
+			{Name: "action", Description: "The type of the action. Possible values are update and remove.", Type: proto.ColumnType_STRING},
+			{Name: "target_count", Description: "The number of objects matched by the target.", Type: proto.ColumnType_INT},
+			{Name: "error", Description: "The error applying the action, if it failed.", Type: proto.ColumnType_STRING},
+			{Name: "path", Description: "Path to the file.", Type: proto.ColumnType_STRING},
+		}),
+	}
+}
+
+type openAPIOverlayAction struct {
+	Path string
+	overlayActionResult
+}
+
+//// LIST FUNCTION
+
+func listOpenAPIOverlayActions(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	// The path comes from a parent hydrate, defaulting to the config paths or
+	// available by the optional key column
+	path := h.Item.(filePath).Path
+
+	// Get the parsed contents, along with the overlay results
+	loaded, err := getLoadedDoc(ctx, d, path)
+	if err != nil {
+		plugin.Logger(ctx).Error("openapi_overlay_action.listOpenAPIOverlayActions", "parse_error", err)
+		return nil, err
+	}
+
+	for _, action := range loaded.OverlayActions {
+		d.StreamListItem(ctx, openAPIOverlayAction{path, *action})
+
+		// Context may get cancelled due to manual cancellation or if the limit has been reached
+		if d.RowsRemaining(ctx) == 0 {
+			return nil, nil
+		}
+	}
+
+	return nil, nil
+}
@@ -23,7 +23,7 @@ func tableOpenAPIPath(ctx context.Context) *plugin.Table {
 			Hydrate:       listOpenAPIPaths,
 			KeyColumns:    plugin.OptionalColumns([]string{"path"}),
 		},
-		Columns: []*plugin.Column{
+		Columns: commonColumns([]*plugin.Column{
 			{Name: "api_path", Description: "A relative path to an individual endpoint.", Type: proto.ColumnType_STRING},
 			{Name: "method", Description: "Specify the HTTP method.", Type: proto.ColumnType_STRING},
 			{Name: "description", Description: "A verbose explanation of the operation behavior.", Type: proto.ColumnType_STRING, Transform: transform.FromField("Operation.Description")},
@@ -40,16 +40,31 @@ func tableOpenAPIPath(ctx context.Context) *plugin.Table {
 			{Name: "servers", Description: "An alternative server array to service this operation. If an alternative server object is specified at the Path Item Object or Root level, it will be overridden by this value.", Type: proto.ColumnType_JSON, Transform: transform.FromField("Operation.Servers")},
 			{Name: "external_docs", Description: "Additional external documentation for this operation.", Type: proto.ColumnType_JSON, Transform: transform.FromField("Operation.ExternalDocs")},
 			{Name: "tags", Description: "A list of tags for API documentation control.", Type: proto.ColumnType_JSON, Transform: transform.FromField("Operation.Tags")},
+
+			// complexity metrics
+			{Name: "max_depth", Description: "The deepest nesting of properties, array items and map values in the parameters, request body and responses of the operation and the schemas they reference, each counted once.", Type: proto.ColumnType_INT, Hydrate: getOpenAPIPathComplexity, Transform: transform.FromField("MaxDepth")},
+			{Name: "property_count", Description: "The number of properties of all objects in the parameters, request body and responses of the operation and the schemas they reference, each counted once.", Type: proto.ColumnType_INT, Hydrate: getOpenAPIPathComplexity, Transform: transform.FromField("PropertyCount")},
+			{Name: "composition_branch_count", Description: "The number of allOf, oneOf and anyOf schemas in the parameters, request body and responses of the operation and the schemas they reference, each counted once.", Type: proto.ColumnType_INT, Hydrate: getOpenAPIPathComplexity, Transform: transform.FromField("CompositionBranchCount")},
+			{Name: "ref_count", Description: "The number of references in the parameters, request body and responses of the operation and the schemas they reference, each counted once.", Type: proto.ColumnType_INT, Hydrate: getOpenAPIPathComplexity, Transform: transform.FromField("RefCount")},
+			{Name: "fan_out_score", Description: "The number of scalar values in the parameters, request body and responses of the operation, where each value counts once more for every array or map it is nested in.", Type: proto.ColumnType_INT, Hydrate: getOpenAPIPathComplexity, Transform: transform.FromField("FanOutScore")},
+
+			// sample requests
+			{Name: "sample_curl", Description: "A curl command sending a sample request to the operation, with its required parameters, placeholder credentials and a synthesized request body.", Type: proto.ColumnType_STRING, Hydrate: getOpenAPIPathSampleRequest, Transform: transform.FromField("Curl")},
+			{Name: "sample_httpie", Description: "An HTTPie command sending the sample request.", Type: proto.ColumnType_STRING, Hydrate: getOpenAPIPathSampleRequest, Transform: transform.FromField("HTTPie")},
+			{Name: "sample_http_request", Description: "The sample request as sent over HTTP/1.1, with CRLF line endings.", Type: proto.ColumnType_STRING, Hydrate: getOpenAPIPathSampleRequest, Transform: transform.FromField("HTTP")},
 			{Name: "path", Description: "Path to the file.", Type: proto.ColumnType_STRING},
-		},
+		}),
 	}
 }
 
 type openAPIPath struct {
-	Path      string
-	ApiPath   string
-	Method    string
-	Operation *openapi3.Operation
+	Path    string
+	ApiPath string
+	Method  string
+	// PathTemplate is the key of the operation in paths, e.g. /pets/{petId}
+	PathTemplate string
+	PathItem     *openapi3.PathItem
+	Operation    *openapi3.Operation
 }
 
 //// LIST FUNCTION
@@ -59,40 +74,63 @@ func listOpenAPIPaths(ctx context.Context, d *plugin.QueryData, h *plugin.Hydrat
 	// available by the optional key column
 	path := h.Item.(filePath).Path
 
-	// Get the parsed contents
-	doc, err := getDoc(ctx, d, path)
+	// Get the indexed contents
+	idx, err := getDocIndex(ctx, d, path)
 	if err != nil {
 		plugin.Logger(ctx).Error("openapi_path.listOpenAPIPaths", "parse_error", err)
 		return nil, err
 	}
 
-	// For each path, scan its arguments
-	for apiPath, item := range doc.Paths {
-		for _, op := range OperationTypes {
-			operation := getOperationInfoByType(op, item)
-
-			// Skip if no method defined
-			if operation == nil {
-				continue
-			}
-
-			d.StreamListItem(ctx, openAPIPath{
-				Path:      path,
-				ApiPath:   p.Join(apiPath, op),
-				Method:    strings.ToUpper(op),
-				Operation: operation,
-			})
-
-			// Context may get cancelled due to manual cancellation or if the limit has been reached
-			if d.RowsRemaining(ctx) == 0 {
-				return nil, nil
-			}
+	// For each operation, scan its arguments
+	for _, op := range idx.Operations {
+		d.StreamListItem(ctx, openAPIPath{
+			Path:         path,
+			ApiPath:      p.Join(op.ApiPath, op.Method),
+			Method:       strings.ToUpper(op.Method),
+			PathTemplate: op.ApiPath,
+			PathItem:     op.PathItem,
+			Operation:    op.Operation,
+		})
+
+		// Context may get cancelled due to manual cancellation or if the limit has been reached
+		if d.RowsRemaining(ctx) == 0 {
+			return nil, nil
 		}
 	}
 
 	return nil, nil
 }
 
+//// HYDRATE FUNCTIONS
+
+func getOpenAPIPathComplexity(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	path := h.Item.(openAPIPath)
+	return measureOperation(path.PathItem, path.Operation), nil
+}
+
+type openAPIPathSampleRequest struct {
+	Curl   string
+	HTTPie string
+	HTTP   string
+}
+
+func getOpenAPIPathSampleRequest(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	path := h.Item.(openAPIPath)
+
+	idx, err := getDocIndex(ctx, d, path.Path)
+	if err != nil {
+		plugin.Logger(ctx).Error("openapi_path.getOpenAPIPathSampleRequest", "parse_error", err)
+		return nil, err
+	}
+
+	req, err := newSampleRequest(idx.Doc, path.Path, path.PathTemplate, path.Method, path.PathItem, path.Operation)
+	if err != nil {
+		plugin.Logger(ctx).Error("openapi_path.getOpenAPIPathSampleRequest", "sample_error", err)
+		return nil, err
+	}
+	return openAPIPathSampleRequest{Curl: req.curl(), HTTPie: req.httpie(), HTTP: req.http()}, nil
+}
+
 func getOperationInfoByType(operationType string, pathItem *openapi3.PathItem) *openapi3.Operation {
 	switch operationType {
 	case "connect":
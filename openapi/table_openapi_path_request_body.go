@@ -22,7 +22,7 @@ func tableOpenAPIPathRequestBody(ctx context.Context) *plugin.Table {
 			Hydrate:       listOpenAPIPathRequestBodies,
 			KeyColumns:    plugin.OptionalColumns([]string{"path"}),
 		},
-		Columns: []*plugin.Column{
+		Columns: commonColumns([]*plugin.Column{
 			{Name: "api_path", Description: "The key of the request body object definition.", Type: proto.ColumnType_STRING},
 			{Name: "api_method", Description: "Specifies the HTTP method.", Type: proto.ColumnType_STRING},
 			{Name: "description", Description: "A description of the request body.", Type: proto.ColumnType_STRING, Transform: transform.FromField("Raw.Description")},
@@ -30,7 +30,7 @@ func tableOpenAPIPathRequestBody(ctx context.Context) *plugin.Table {
 			{Name: "request_body_ref", Description: "The reference to the components request body object.", Type: proto.ColumnType_STRING},
 			{Name: "content", Description: "A map containing descriptions of potential request body payloads.", Type: proto.ColumnType_JSON},
 			{Name: "path", Description: "Path to the file.", Type: proto.ColumnType_STRING},
-		},
+		}),
 	}
 }
 
@@ -50,57 +50,50 @@ func listOpenAPIPathRequestBodies(ctx context.Context, d *plugin.QueryData, h *p
 	// available by the optional key column
 	path := h.Item.(filePath).Path
 
-	// Get the parsed contents
-	doc, err := getDoc(ctx, d, path)
+	// Get the indexed contents
+	idx, err := getDocIndex(ctx, d, path)
 	if err != nil {
 		plugin.Logger(ctx).Error("openapi_path_request_body.listOpenAPIPathRequestBodies", "parse_error", err)
 		return nil, err
 	}
 
-	// For each path, scan its request body object arguments
-	for apiPath, item := range doc.Paths {
-		for _, op := range OperationTypes {
-			operation := getOperationInfoByType(op, item)
-
-			// Skip if no method defined
-			if operation == nil {
-				continue
-			}
+	// For each operation, scan its request body object arguments
+	for _, op := range idx.Operations {
+		operation := op.Operation
 
-			// Skip if no request body defined
-			if operation.RequestBody == nil {
-				continue
-			}
+		// Skip if no request body defined
+		if operation.RequestBody == nil {
+			continue
+		}
 
-			requestBodyObject := openAPIPathRequestBody{
-				Path:           path,
-				ApiPath:        p.Join(apiPath, op),
-				ApiMethod:      strings.ToUpper(op),
-				RequestBodyRef: operation.RequestBody.Ref,
-			}
+		requestBodyObject := openAPIPathRequestBody{
+			Path:           path,
+			ApiPath:        p.Join(op.ApiPath, op.Method),
+			ApiMethod:      strings.ToUpper(op.Method),
+			RequestBodyRef: operation.RequestBody.Ref,
+		}
 
-			for header, content := range operation.RequestBody.Value.Content {
-				var schema interface{}
-				if content.Schema.Ref != "" {
-					schema = content.Schema.Ref
-				} else {
-					schema = content.Schema
-				}
-				requestBodyObject.Content = append(requestBodyObject.Content, map[string]interface{}{
-					"contentType": header,
-					"examples":    content.Examples,
-					"schema":      schema,
-					"schemaType":  content.Schema.Value.Type,
-					"encoding":    content.Encoding,
-				})
-				requestBodyObject.Raw = *operation.RequestBody.Value
+		for header, content := range operation.RequestBody.Value.Content {
+			var schema interface{}
+			if content.Schema.Ref != "" {
+				schema = content.Schema.Ref
+			} else {
+				schema = content.Schema
 			}
-			d.StreamListItem(ctx, requestBodyObject)
+			requestBodyObject.Content = append(requestBodyObject.Content, map[string]interface{}{
+				"contentType": header,
+				"examples":    content.Examples,
+				"schema":      schema,
+				"schemaType":  content.Schema.Value.Type,
+				"encoding":    content.Encoding,
+			})
+			requestBodyObject.Raw = *operation.RequestBody.Value
+		}
+		d.StreamListItem(ctx, requestBodyObject)
 
-			// Context may get cancelled due to manual cancellation or if the limit has been reached
-			if d.RowsRemaining(ctx) == 0 {
-				return nil, nil
-			}
+		// Context may get cancelled due to manual cancellation or if the limit has been reached
+		if d.RowsRemaining(ctx) == 0 {
+			return nil, nil
 		}
 	}
 
@@ -22,7 +22,7 @@ func tableOpenAPIPathResponse(ctx context.Context) *plugin.Table {
 			Hydrate:       listOpenAPIPathResponses,
 			KeyColumns:    plugin.OptionalColumns([]string{"path"}),
 		},
-		Columns: []*plugin.Column{
+		Columns: commonColumns([]*plugin.Column{
 			{Name: "api_path", Description: "The key of the response object definition.", Type: proto.ColumnType_STRING},
 			{Name: "api_method", Description: "Specifies the HTTP method.", Type: proto.ColumnType_STRING},
 			{Name: "response_status", Description: "The key of the response object definition.", Type: proto.ColumnType_STRING},
@@ -31,8 +31,10 @@ func tableOpenAPIPathResponse(ctx context.Context) *plugin.Table {
 			{Name: "headers", Description: "Maps a header name to its definition.", Type: proto.ColumnType_JSON, Transform: transform.FromField("Raw.Headers")},
 			{Name: "links", Description: "A map of operations links that can be followed from the response.", Type: proto.ColumnType_JSON, Transform: transform.FromField("Raw.Links")},
 			{Name: "description", Description: "A description of the response.", Type: proto.ColumnType_STRING},
+			{Name: "sample_response", Description: "A payload synthesized for the response, from the example of its content or else its schema. JSON content is preferred if the response has several content types.", Type: proto.ColumnType_JSON, Hydrate: getOpenAPIPathResponseSample, Transform: transform.FromField("Value")},
+			{Name: "sample_response_content_type", Description: "The content type of the sample response.", Type: proto.ColumnType_STRING, Hydrate: getOpenAPIPathResponseSample, Transform: transform.FromField("ContentType").NullIfZero()},
 			{Name: "path", Description: "Path to the file.", Type: proto.ColumnType_STRING},
-		},
+		}),
 	}
 }
 
@@ -54,58 +56,73 @@ func listOpenAPIPathResponses(ctx context.Context, d *plugin.QueryData, h *plugi
 	// available by the optional key column
 	path := h.Item.(filePath).Path
 
-	// Get the parsed contents
-	doc, err := getDoc(ctx, d, path)
+	// Get the indexed contents
+	idx, err := getDocIndex(ctx, d, path)
 	if err != nil {
 		plugin.Logger(ctx).Error("openapi_path_response.listOpenAPIPathResponses", "parse_error", err)
 		return nil, err
 	}
 
-	// For each path, scan its response object arguments
-	for apiPath, item := range doc.Paths {
-		for _, op := range OperationTypes {
-			operation := getOperationInfoByType(op, item)
+	// For each operation response, scan its arguments
+	for _, r := range idx.Responses {
+		response := r.Response
+		responseObject := openAPIPathResponse{
+			Path:           path,
+			ApiPath:        p.Join(r.ApiPath, r.Method),
+			ApiMethod:      strings.ToUpper(r.Method),
+			ResponseStatus: r.Status,
+			Description:    *response.Value.Description,
+			ResponseRef:    response.Ref,
+		}
 
-			// Skip if no method defined
-			if operation == nil {
-				continue
+		for header, content := range response.Value.Content {
+			var schema interface{}
+			if content.Schema.Ref != "" {
+				schema = content.Schema.Ref
+			} else {
+				schema = content.Schema
 			}
+			responseObject.Content = append(responseObject.Content, map[string]interface{}{
+				"contentType": header,
+				"examples":    content.Examples,
+				"schema":      schema,
+				"schemaType":  content.Schema.Value.Type,
+			})
+		}
+		responseObject.Raw = *response.Value
 
-			for responseStatus, response := range operation.Responses {
-				responseObject := openAPIPathResponse{
-					Path:           path,
-					ApiPath:        p.Join(apiPath, op),
-					ApiMethod:      strings.ToUpper(op),
-					ResponseStatus: responseStatus,
-					Description:    *response.Value.Description,
-					ResponseRef:    response.Ref,
-				}
-
-				for header, content := range response.Value.Content {
-					var schema interface{}
-					if content.Schema.Ref != "" {
-						schema = content.Schema.Ref
-					} else {
-						schema = content.Schema
-					}
-					responseObject.Content = append(responseObject.Content, map[string]interface{}{
-						"contentType": header,
-						"examples":    content.Examples,
-						"schema":      schema,
-						"schemaType":  content.Schema.Value.Type,
-					})
-				}
-				responseObject.Raw = *response.Value
-
-				d.StreamListItem(ctx, responseObject)
-
-				// Context may get cancelled due to manual cancellation or if the limit has been reached
-				if d.RowsRemaining(ctx) == 0 {
-					return nil, nil
-				}
-			}
+		d.StreamListItem(ctx, responseObject)
+
+		// Context may get cancelled due to manual cancellation or if the limit has been reached
+		if d.RowsRemaining(ctx) == 0 {
+			return nil, nil
 		}
 	}
 
 	return nil, nil
 }
+
+//// HYDRATE FUNCTIONS
+
+type openAPISample struct {
+	ContentType string
+	Value       interface{}
+}
+
+// getOpenAPIPathResponseSample synthesizes a payload for the response. The
+// generator is seeded by the operation and status, so the sample only
+// changes when the response does.
+func getOpenAPIPathResponseSample(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	response := h.Item.(openAPIPathResponse)
+	contentType := sampleContentType(response.Raw.Content)
+	if contentType == "" {
+		return openAPISample{}, nil
+	}
+	g := newSampleGenerator(response.ApiPath+" "+response.ResponseStatus, false)
+	value, err := toJSONValue(g.mediaTypeValue(response.Raw.Content[contentType]))
+	if err != nil {
+		plugin.Logger(ctx).Error("openapi_path_response.getOpenAPIPathResponseSample", "sample_error", err)
+		return nil, err
+	}
+	return openAPISample{ContentType: contentType, Value: value}, nil
+}
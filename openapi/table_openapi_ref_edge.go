@@ -0,0 +1,294 @@
+package openapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/invopop/yaml"
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+//// TABLE DEFINITION
+
+func tableOpenAPIRefEdge(ctx context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "openapi_ref_edge",
+		Description: "References between objects of a definition and the files it references.",
+		List: &plugin.ListConfig{
+			ParentHydrate: listOpenAPIFiles,
+			Hydrate:       listOpenAPIRefEdges,
+			KeyColumns:    plugin.OptionalColumns([]string{"path"}),
+		},
+		Columns: commonColumns([]*plugin.Column{
+			{Name: "from_pointer", Description: "The JSON pointer to the object containing the reference, in the source file.", Type: proto.ColumnType_STRING},
+			{Name: "to_pointer", Description: "The JSON pointer to the referenced object, in the target file. Empty if the reference is to the whole target file.", Type: proto.ColumnType_STRING},
+			{Name: "ref", Description: "The value of the $ref, as written in the source file.", Type: proto.ColumnType_STRING},
+			{Name: "kind", Description: "The kind of object referenced, e.g. schema, parameter or response.", Type: proto.ColumnType_STRING},
+			{Name: "source_file", Description: "The file containing the reference.", Type: proto.ColumnType_STRING},
+			{Name: "target_file", Description: "The file containing the referenced object.", Type: proto.ColumnType_STRING},
+			{Name: "path", Description: "Path to the root file.", Type: proto.ColumnType_STRING},
+		}),
+	}
+}
+
+type openAPIRefEdge struct {
+	Path        string
+	FromPointer string
+	ToPointer   string
+	Ref         string
+	Kind        string
+	SourceFile  string
+	TargetFile  string
+}
+
+//// LIST FUNCTION
+
+func listOpenAPIRefEdges(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	// The path comes from a parent hydrate, defaulting to the config paths or
+	// available by the optional key column
+	path := h.Item.(filePath).Path
+
+	// Get the indexed contents. Referenced files are read as they are found,
+	// and walked for references of their own, only if allow_external_refs is
+	// set.
+	config := GetConfig(d.Connection)
+	externalRefs := config.AllowExternalRefs != nil && *config.AllowExternalRefs
+	loader, err := newDocLoader(ctx, d, path, externalRefs)
+	if err != nil {
+		plugin.Logger(ctx).Error("openapi_ref_edge.listOpenAPIRefEdges", "cache_error", err)
+		return nil, err
+	}
+
+	var edges []*openAPIRefEdge
+	idx, err := getDocIndex(ctx, d, path)
+	switch {
+	case err == nil:
+		for _, ref := range idx.Refs {
+			edges = append(edges, newRefEdge(path, path, ref.Pointer, ref.Ref, ref.Kind))
+		}
+	case !externalRefs:
+		// The definition fails to load if it references other files, so the
+		// references are taken from the root file alone, without following
+		// them
+		value, err := readRefFile(loader, path)
+		if err != nil {
+			plugin.Logger(ctx).Error("openapi_ref_edge.listOpenAPIRefEdges", "file_error", err)
+			return nil, err
+		}
+		walkRefs(value, "", nil, func(ptr string, ref string, kind string) {
+			edges = append(edges, newRefEdge(path, path, ptr, ref, kind))
+		})
+	default:
+		plugin.Logger(ctx).Error("openapi_ref_edge.listOpenAPIRefEdges", "parse_error", err)
+		return nil, err
+	}
+
+	visited := map[string]bool{path: true}
+	for i := 0; externalRefs && i < len(edges); i++ {
+		file := edges[i].TargetFile
+		if visited[file] {
+			continue
+		}
+		visited[file] = true
+
+		value, err := readRefFile(loader, file)
+		if err != nil {
+			plugin.Logger(ctx).Error("openapi_ref_edge.listOpenAPIRefEdges", "file_error", err, "file", file)
+			return nil, fmt.Errorf("failed to read file %s referenced by %s: %v", file, path, err)
+		}
+		walkRefs(value, "", nil, func(ptr string, ref string, kind string) {
+			edges = append(edges, newRefEdge(path, file, ptr, ref, kind))
+		})
+	}
+
+	for _, edge := range edges {
+		d.StreamListItem(ctx, edge)
+
+		// Context may get cancelled due to manual cancellation or if the limit has been reached
+		if d.RowsRemaining(ctx) == 0 {
+			return nil, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func newRefEdge(path string, source string, ptr string, ref string, kind string) *openAPIRefEdge {
+	file, fragment, _ := strings.Cut(ref, "#")
+	edge := &openAPIRefEdge{
+		Path:        path,
+		FromPointer: ptr,
+		ToPointer:   fragment,
+		Ref:         ref,
+		Kind:        kind,
+		SourceFile:  source,
+		TargetFile:  resolveRefFile(source, file),
+	}
+	if edge.Kind == "" {
+		edge.Kind = refKindFromPointer(fragment)
+	}
+	return edge
+}
+
+// resolveRefFile returns the location of the file part of a reference, which
+// is relative to the file containing the reference. An empty file refers to
+// the source file itself.
+func resolveRefFile(source string, file string) string {
+	switch {
+	case file == "":
+		return source
+	case isHTTPURL(source):
+		base, err := url.Parse(source)
+		if err != nil {
+			return file
+		}
+		ref, err := url.Parse(file)
+		if err != nil {
+			return file
+		}
+		return base.ResolveReference(ref).String()
+	case isHTTPURL(file) || filepath.IsAbs(file):
+		return file
+	}
+	return filepath.Join(filepath.Dir(source), filepath.FromSlash(file))
+}
+
+// readRefFile reads a referenced file as generic JSON values, using the same
+// readers as the loader so that URLs are fetched with the configured options.
+func readRefFile(loader *openapi3.Loader, file string) (interface{}, error) {
+	location := &url.URL{Path: filepath.ToSlash(file)}
+	if isHTTPURL(file) {
+		var err error
+		if location, err = url.Parse(file); err != nil {
+			return nil, err
+		}
+	}
+	data, err := loader.ReadFromURIFunc(loader, location)
+	if err != nil {
+		return nil, err
+	}
+	if data, err = yaml.YAMLToJSON(data); err != nil {
+		return nil, err
+	}
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// refNamedMaps are the keywords of maps whose keys are names, e.g. of
+// properties or response codes, rather than keywords
+var refNamedMaps = map[string]bool{
+	"properties": true, "definitions": true, "paths": true, "responses": true,
+	"callbacks": true, "content": true, "encoding": true, "headers": true,
+	"links": true, "examples": true, "variables": true, "mapping": true,
+	"schemas": true, "parameters": true, "requestBodies": true, "securitySchemes": true,
+}
+
+// isRefNamedMap returns true if tokens point to a map whose keys are names.
+// A keyword used as a name, e.g. a property called properties, is not one.
+func isRefNamedMap(tokens []string) bool {
+	named := false
+	for _, token := range tokens {
+		named = !named && refNamedMaps[token]
+	}
+	return named
+}
+
+// walkRefs calls fn for every $ref in v, a file as generic JSON values, with
+// the pointer of the object containing it and the kind of object inferred
+// from where the reference is. Example values are data rather than part of
+// the definition, so references within them are skipped. Keys of maps of
+// names, e.g. a property called example, are never skipped.
+func walkRefs(v interface{}, ptr string, tokens []string, fn func(ptr string, ref string, kind string)) {
+	switch c := v.(type) {
+	case map[string]interface{}:
+		if ref, ok := c["$ref"].(string); ok {
+			fn(ptr, ref, refKindFromLocation(tokens))
+			return
+		}
+		keywords := !isRefNamedMap(tokens)
+		example := len(tokens) > 1 && tokens[len(tokens)-2] == "examples" && isRefNamedMap(tokens[:len(tokens)-1])
+		for _, k := range sortedKeys(c) {
+			if keywords && (k == "example" || k == "default" || k == "enum" || (k == "value" && example)) {
+				continue
+			}
+			walkRefs(c[k], pointerJoin(ptr, k), append(tokens, k), fn)
+		}
+	case []interface{}:
+		for i, item := range c {
+			k := strconv.Itoa(i)
+			walkRefs(item, pointerJoin(ptr, k), append(tokens, k), fn)
+		}
+	}
+}
+
+// refKindFromLocation infers the kind of object referenced from the tokens
+// of the pointer where the reference is, e.g. a reference in the items of a
+// parameters list is to a parameter.
+func refKindFromLocation(tokens []string) string {
+	if len(tokens) == 0 {
+		return ""
+	}
+	last := tokens[len(tokens)-1]
+	parent := ""
+	if len(tokens) > 1 {
+		parent = tokens[len(tokens)-2]
+	}
+
+	if len(tokens) == 3 && tokens[0] == "components" {
+		for _, section := range componentSections {
+			if section.Section == parent {
+				return section.Kind
+			}
+		}
+	}
+	switch parent {
+	case "properties", "allOf", "oneOf", "anyOf":
+		return "schema"
+	case "parameters":
+		return "parameter"
+	case "headers":
+		return "header"
+	case "responses":
+		return "response"
+	case "examples":
+		return "example"
+	case "links":
+		return "link"
+	case "callbacks":
+		return "callback"
+	case "paths":
+		return "pathItem"
+	}
+	switch last {
+	case "schema", "items", "not", "additionalProperties":
+		return "schema"
+	case "requestBody":
+		return "requestBody"
+	}
+	return ""
+}
+
+// refKindFromPointer infers the kind of object referenced from the pointer
+// it references, e.g. /components/schemas/Pet is a schema.
+func refKindFromPointer(ptr string) string {
+	tokens := strings.Split(ptr, "/")
+	if len(tokens) < 3 || tokens[0] != "" || tokens[1] != "components" {
+		return ""
+	}
+	for _, section := range componentSections {
+		if section.Section == tokens[2] {
+			return section.Kind
+		}
+	}
+	return ""
+}
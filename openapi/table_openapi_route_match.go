@@ -0,0 +1,112 @@
+package openapi
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+//// TABLE DEFINITION
+
+func tableOpenAPIRouteMatch(ctx context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "openapi_route_match",
+		Description: "The operations matching a request URL, with the values of their path parameters.",
+		List: &plugin.ListConfig{
+			Hydrate: listOpenAPIRouteMatches,
+			KeyColumns: plugin.KeyColumnSlice{
+				{Name: "url", Require: plugin.Required},
+				{Name: "method", Require: plugin.Optional},
+				{Name: "path", Require: plugin.Optional},
+			},
+		},
+		Columns: commonColumns([]*plugin.Column{
+			{Name: "url", Description: "The URL of the request, e.g. https://api.example.com/v1/pets/42 or /pets/42.", Type: proto.ColumnType_STRING},
+			{Name: "method", Description: "The HTTP method of the matching operation. If not given in the where clause, operations of every method are matched.", Type: proto.ColumnType_STRING},
+			{Name: "operation_api_path", Description: "The API path of the matching operation, e.g. /pets/{petId}.", Type: proto.ColumnType_STRING},
+			{Name: "operation_id", Description: "The operationId of the matching operation.", Type: proto.ColumnType_STRING, Transform: transform.FromField("OperationId").NullIfZero()},
+			{Name: "path_params", Description: "The values of the path parameters, extracted from the URL.", Type: proto.ColumnType_JSON},
+			{Name: "base_path", Description: "The path of the server URL removed from the URL path before matching, e.g. /v1.", Type: proto.ColumnType_STRING, Transform: transform.FromField("BasePath").NullIfZero()},
+			{Name: "rank", Description: "The position of the match among the matches of the same method in the definition, starting at 1. Paths with literal segments rank before templated paths.", Type: proto.ColumnType_INT},
+			{Name: "is_best_match", Description: "True if the operation is the one the request is routed to in the definition.", Type: proto.ColumnType_BOOL},
+			{Name: "is_ambiguous", Description: "True if another path of the definition matches the URL equally well, e.g. /users/{id} and /users/{name}, which the OpenAPI specification leaves undefined.", Type: proto.ColumnType_BOOL},
+			{Name: "path", Description: "Path to the file.", Type: proto.ColumnType_STRING},
+		}),
+	}
+}
+
+type openAPIRouteMatch struct {
+	Path             string
+	URL              string
+	Method           string
+	OperationApiPath string
+	OperationId      string
+	PathParams       map[string]string
+	BasePath         string
+	Rank             int
+	IsBestMatch      bool
+	IsAmbiguous      bool
+}
+
+//// LIST FUNCTION
+
+func listOpenAPIRouteMatches(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	rawURL := d.EqualsQualString("url")
+	method := d.EqualsQualString("method")
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		plugin.Logger(ctx).Error("openapi_route_match.listOpenAPIRouteMatches", "url_error", err)
+		return nil, err
+	}
+
+	routers, err := specRouters(ctx, d)
+	if err != nil {
+		plugin.Logger(ctx).Error("openapi_route_match.listOpenAPIRouteMatches", "parse_error", err)
+		return nil, err
+	}
+
+	for _, r := range routers {
+		matches := r.Router.match(method, u.EscapedPath())
+
+		// Matches are ranked separately for each method, since a request
+		// only has one
+		rank := map[string]int{}
+		for i, m := range matches {
+			opMethod := strings.ToUpper(m.Operation.Method)
+			rank[opMethod]++
+			row := openAPIRouteMatch{
+				Path:             r.Path,
+				URL:              rawURL,
+				Method:           opMethod,
+				OperationApiPath: m.Operation.ApiPath,
+				OperationId:      m.Operation.Operation.OperationID,
+				PathParams:       m.PathParams,
+				BasePath:         m.BasePath,
+				Rank:             rank[opMethod],
+				IsBestMatch:      rank[opMethod] == 1,
+			}
+			// The qual is returned as given so that it matches
+			if method != "" {
+				row.Method = method
+			}
+			for j, other := range matches {
+				if j != i && strings.EqualFold(other.Operation.Method, m.Operation.Method) && other.Operation.ApiPath != m.Operation.ApiPath && compareRouteLiterals(other.Operation.ApiPath, m.Operation.ApiPath) == 0 {
+					row.IsAmbiguous = true
+				}
+			}
+			d.StreamListItem(ctx, row)
+
+			// Context may get cancelled due to manual cancellation or if the limit has been reached
+			if d.RowsRemaining(ctx) == 0 {
+				return nil, nil
+			}
+		}
+	}
+
+	return nil, nil
+}
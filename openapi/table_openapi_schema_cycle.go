@@ -0,0 +1,291 @@
+package openapi
+
+import (
+	"context"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+// maxSchemaCycles limits the cycles listed per document. The number of cycles
+// can grow exponentially with the number of schemas referencing each other.
+const maxSchemaCycles = 1000
+
+//// TABLE DEFINITION
+
+func tableOpenAPISchemaCycle(ctx context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "openapi_schema_cycle",
+		Description: "Reference cycles between the component schemas of each definition file.",
+		List: &plugin.ListConfig{
+			ParentHydrate: listOpenAPIFiles,
+			Hydrate:       listOpenAPISchemaCycles,
+			KeyColumns:    plugin.OptionalColumns([]string{"path"}),
+		},
+		Columns: commonColumns([]*plugin.Column{
+			{Name: "schemas", Description: "The names of the schemas forming the cycle, in reference order, starting with the first by name. The last schema references the first.", Type: proto.ColumnType_JSON},
+			{Name: "length", Description: "The number of schemas in the cycle.", Type: proto.ColumnType_INT},
+			{Name: "is_broken", Description: "True if at least one reference in the cycle is through an optional property, a nullable schema, an array, a map or one of several alternatives, so a finite value can satisfy the schemas.", Type: proto.ColumnType_BOOL},
+			{Name: "broken_by", Description: "The references of the cycle which break it, with the reason: optional_property, nullable, array, map or alternative.", Type: proto.ColumnType_JSON},
+			{Name: "path", Description: "Path to the file.", Type: proto.ColumnType_STRING},
+		}),
+	}
+}
+
+type openAPISchemaCycle struct {
+	Path     string
+	Schemas  []string
+	Length   int
+	IsBroken bool
+	BrokenBy []schemaCycleBreak
+}
+
+type schemaCycleBreak struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Reason string `json:"reason"`
+}
+
+//// LIST FUNCTION
+
+func listOpenAPISchemaCycles(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	// The path comes from a parent hydrate, defaulting to the config paths or
+	// available by the optional key column
+	path := h.Item.(filePath).Path
+
+	// Get the parsed contents
+	doc, err := getDoc(ctx, d, path)
+	if err != nil {
+		plugin.Logger(ctx).Error("openapi_schema_cycle.listOpenAPISchemaCycles", "parse_error", err)
+		return nil, err
+	}
+
+	graph := buildSchemaGraph(doc)
+	cycles := graph.cycles(maxSchemaCycles)
+	if len(cycles) == maxSchemaCycles {
+		plugin.Logger(ctx).Warn("openapi_schema_cycle.listOpenAPISchemaCycles", "path", path, "limit", maxSchemaCycles, "status", "too many cycles, results truncated")
+	}
+
+	for _, cycle := range cycles {
+		row := openAPISchemaCycle{
+			Path:     path,
+			Schemas:  cycle,
+			Length:   len(cycle),
+			BrokenBy: []schemaCycleBreak{},
+		}
+		for i, from := range cycle {
+			to := cycle[(i+1)%len(cycle)]
+			if reason := graph[from][to]; reason != "" {
+				row.BrokenBy = append(row.BrokenBy, schemaCycleBreak{from, to, reason})
+			}
+		}
+		row.IsBroken = len(row.BrokenBy) > 0
+		d.StreamListItem(ctx, row)
+
+		// Context may get cancelled due to manual cancellation or if the limit has been reached
+		if d.RowsRemaining(ctx) == 0 {
+			return nil, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// schemaGraph holds the references between component schemas, by name. The
+// value of each edge is the reason a value of the referencing schema does
+// not need to contain the referenced one, or empty if it always does.
+type schemaGraph map[string]map[string]string
+
+func buildSchemaGraph(doc *openapi3.T) schemaGraph {
+	g := schemaGraph{}
+	if doc.Components == nil {
+		return g
+	}
+	for _, name := range sortedKeys(doc.Components.Schemas) {
+		g[name] = map[string]string{}
+		g.walk(name, doc.Components.Schemas[name], "")
+	}
+	return g
+}
+
+// walk records the references from the schema named from found in s. It
+// never follows a reference, so each schema is only walked where it is
+// declared.
+func (g schemaGraph) walk(from string, s *openapi3.SchemaRef, reason string) {
+	if s == nil {
+		return
+	}
+	if s.Ref != "" {
+		if strings.HasPrefix(s.Ref, "#/components/schemas/") {
+			g.addEdge(from, schemaNameFromRef(s.Ref), reason)
+		}
+		return
+	}
+	v := s.Value
+	if v == nil {
+		return
+	}
+
+	if v.Nullable && reason == "" {
+		reason = "nullable"
+	}
+	for _, sub := range v.AllOf {
+		g.walk(from, sub, reason)
+	}
+	for _, alternatives := range []openapi3.SchemaRefs{v.OneOf, v.AnyOf} {
+		r := reason
+		if r == "" && len(alternatives) > 1 {
+			r = "alternative"
+		}
+		for _, sub := range alternatives {
+			g.walk(from, sub, r)
+		}
+	}
+	if r := reason; v.Items != nil {
+		if r == "" && v.MinItems == 0 {
+			r = "array"
+		}
+		g.walk(from, v.Items, r)
+	}
+	required := map[string]bool{}
+	for _, name := range v.Required {
+		required[name] = true
+	}
+	for _, name := range sortedKeys(v.Properties) {
+		r := reason
+		if r == "" && !required[name] {
+			r = "optional_property"
+		}
+		g.walk(from, v.Properties[name], r)
+	}
+	if v.AdditionalProperties.Schema != nil {
+		r := reason
+		if r == "" {
+			r = "map"
+		}
+		g.walk(from, v.AdditionalProperties.Schema, r)
+	}
+}
+
+// addEdge records a reference. A reference which must always be followed
+// takes precedence over one which need not be.
+func (g schemaGraph) addEdge(from string, to string, reason string) {
+	if existing, ok := g[from][to]; ok && (existing == "" || reason != "") {
+		return
+	}
+	g[from][to] = reason
+}
+
+// cycles returns the elementary cycles of the graph, up to limit, using
+// Johnson's algorithm. Each cycle starts at its first schema by name, so
+// every cycle is found exactly once.
+func (g schemaGraph) cycles(limit int) [][]string {
+	names := sortedKeys(g)
+	reverse := map[string][]string{}
+	for _, from := range names {
+		for to := range g[from] {
+			reverse[to] = append(reverse[to], from)
+		}
+	}
+
+	var cycles [][]string
+	for i, start := range names {
+		if len(cycles) >= limit {
+			break
+		}
+		// Cycles through start only go through the schemas it reaches and
+		// that reach it, among those after it. Cycles through an earlier
+		// schema were found when starting from it.
+		later := map[string]bool{}
+		for _, name := range names[i:] {
+			later[name] = true
+		}
+		forward := g.reachable(start, later, func(n string) []string { return sortedKeys(g[n]) })
+		backward := g.reachable(start, later, func(n string) []string { return reverse[n] })
+		component := map[string]bool{}
+		for name := range forward {
+			if backward[name] {
+				component[name] = true
+			}
+		}
+
+		blocked := map[string]bool{}
+		blockedBy := map[string]map[string]bool{}
+		var unblock func(node string)
+		unblock = func(node string) {
+			blocked[node] = false
+			for other := range blockedBy[node] {
+				delete(blockedBy[node], other)
+				if blocked[other] {
+					unblock(other)
+				}
+			}
+		}
+
+		var stack []string
+		var circuit func(node string) bool
+		circuit = func(node string) bool {
+			found := false
+			stack = append(stack, node)
+			blocked[node] = true
+			for _, next := range sortedKeys(g[node]) {
+				if len(cycles) >= limit {
+					return true
+				}
+				if !component[next] {
+					continue
+				}
+				if next == start {
+					cycles = append(cycles, append([]string{}, stack...))
+					found = true
+				} else if !blocked[next] && circuit(next) {
+					found = true
+				}
+			}
+			if found {
+				unblock(node)
+			} else {
+				for next := range g[node] {
+					if component[next] {
+						if blockedBy[next] == nil {
+							blockedBy[next] = map[string]bool{}
+						}
+						blockedBy[next][node] = true
+					}
+				}
+			}
+			stack = stack[:len(stack)-1]
+			return found
+		}
+		circuit(start)
+	}
+	return cycles
+}
+
+// reachable returns the schemas in allowed reachable from start, including
+// start, following the edges returned by next
+func (g schemaGraph) reachable(start string, allowed map[string]bool, next func(string) []string) map[string]bool {
+	seen := map[string]bool{start: true}
+	queue := []string{start}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		for _, other := range next(node) {
+			if allowed[other] && !seen[other] {
+				seen[other] = true
+				queue = append(queue, other)
+			}
+		}
+	}
+	return seen
+}
+
+// schemaNameFromRef returns the name of the component schema a local
+// reference points to, e.g. Pet for #/components/schemas/Pet
+func schemaNameFromRef(ref string) string {
+	ptr := componentPointer(strings.TrimPrefix(ref, "#"))
+	name := ptr[strings.LastIndex(ptr, "/")+1:]
+	return strings.ReplaceAll(strings.ReplaceAll(name, "~1", "/"), "~0", "~")
+}
@@ -0,0 +1,369 @@
+package openapi
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+// defaultMinSchemaSimilarity is the similarity from which two schemas are
+// listed as near duplicates, unless min_similarity is given
+const defaultMinSchemaSimilarity = 0.8
+
+//// TABLE DEFINITION
+
+func tableOpenAPISchemaDuplicate(ctx context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "openapi_schema_duplicate",
+		Description: "Groups of identical or similar component schemas across all definition files, with a row for each pair of schemas compared.",
+		List: &plugin.ListConfig{
+			Hydrate: listOpenAPISchemaDuplicates,
+			KeyColumns: plugin.KeyColumnSlice{
+				{Name: "min_similarity", Require: plugin.Optional},
+			},
+		},
+		Columns: commonColumns([]*plugin.Column{
+			{Name: "group_id", Description: "The structural hash of the first schema of the group of identical and similar schemas, by path and name. Schemas similar to a schema of the group are in it, even if they are not similar to each other.", Type: proto.ColumnType_STRING},
+			{Name: "name", Description: "The name of the schema.", Type: proto.ColumnType_STRING},
+			{Name: "other_name", Description: "The name of the similar schema.", Type: proto.ColumnType_STRING},
+			{Name: "other_path", Description: "Path to the file of the similar schema.", Type: proto.ColumnType_STRING},
+			{Name: "structural_hash", Description: "The SHA-256 hash of the canonical structure of the schema. Identical schemas have the same hash.", Type: proto.ColumnType_STRING},
+			{Name: "other_structural_hash", Description: "The SHA-256 hash of the canonical structure of the similar schema.", Type: proto.ColumnType_STRING},
+			{Name: "is_identical", Description: "True if both schemas have the same structure.", Type: proto.ColumnType_BOOL},
+			{Name: "similarity", Description: "The share of the structure the schemas have in common, between min_similarity and 1.", Type: proto.ColumnType_DOUBLE},
+			{Name: "min_similarity", Description: "The similarity from which schemas are listed as similar, between 0 and 1. Defaults to 0.8.", Type: proto.ColumnType_DOUBLE},
+			{Name: "differing_properties", Description: "The names of the top level properties that differ between the schemas, or are only in one of them.", Type: proto.ColumnType_JSON},
+			{Name: "path", Description: "Path to the file of the schema.", Type: proto.ColumnType_STRING},
+		}),
+	}
+}
+
+type openAPISchemaDuplicate struct {
+	GroupId             string
+	Path                string
+	Name                string
+	OtherPath           string
+	OtherName           string
+	StructuralHash      string
+	OtherStructuralHash string
+	IsIdentical         bool
+	Similarity          float64
+	MinSimilarity       float64
+	DifferingProperties []string
+}
+
+// canonicalSchema is a component schema reduced to its structure
+type canonicalSchema struct {
+	Path  string
+	Name  string
+	Value interface{}
+	Hash  string
+	// Facts holds every leaf of the structure as pointer=value
+	Facts map[string]bool
+}
+
+//// LIST FUNCTION
+
+func listOpenAPISchemaDuplicates(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	minSimilarity := defaultMinSchemaSimilarity
+	if d.EqualsQuals["min_similarity"] != nil {
+		minSimilarity = d.EqualsQuals["min_similarity"].GetDoubleValue()
+		if minSimilarity <= 0 || minSimilarity > 1 {
+			return nil, fmt.Errorf("min_similarity must be greater than 0 and at most 1, got %v", minSimilarity)
+		}
+	}
+
+	files, err := openAPIFiles(ctx, d)
+	if err != nil {
+		plugin.Logger(ctx).Error("openapi_schema_duplicate.listOpenAPISchemaDuplicates", "config_error", err)
+		return nil, err
+	}
+
+	var schemas []*canonicalSchema
+	for _, f := range files {
+		s, err := canonicalSchemas(ctx, d, f.Path)
+		if err != nil {
+			plugin.Logger(ctx).Error("openapi_schema_duplicate.listOpenAPISchemaDuplicates", "parse_error", err, "path", f.Path)
+			return nil, err
+		}
+		schemas = append(schemas, s...)
+	}
+	sort.SliceStable(schemas, func(i, j int) bool {
+		if schemas[i].Path != schemas[j].Path {
+			return schemas[i].Path < schemas[j].Path
+		}
+		return schemas[i].Name < schemas[j].Name
+	})
+
+	for _, row := range schemaDuplicates(schemas, minSimilarity) {
+		d.StreamListItem(ctx, row)
+
+		// Context may get cancelled due to manual cancellation or if the limit has been reached
+		if d.RowsRemaining(ctx) == 0 {
+			return nil, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// schemaDuplicates returns the groups of identical and similar schemas.
+// Identical schemas are found by hash, and each listed with the first of
+// them. Only one schema of each hash is compared with the others, and only
+// with those sharing enough facts to reach minSimilarity, found by prefix
+// filtering: ordering the facts of every schema from the rarest, two schemas
+// with a similarity of at least minSimilarity share one of the first
+// n - ceil(n * minSimilarity) + 1 facts of either.
+func schemaDuplicates(schemas []*canonicalSchema, minSimilarity float64) []openAPISchemaDuplicate {
+	var rows []openAPISchemaDuplicate
+
+	// Identical schemas
+	var representatives []*canonicalSchema
+	first := map[string]*canonicalSchema{}
+	for _, s := range schemas {
+		if f, ok := first[s.Hash]; ok {
+			rows = append(rows, compareSchemas(f, s))
+			continue
+		}
+		first[s.Hash] = s
+		representatives = append(representatives, s)
+	}
+
+	// Similar schemas
+	frequency := map[string]int{}
+	for _, s := range representatives {
+		for fact := range s.Facts {
+			frequency[fact]++
+		}
+	}
+	candidates := map[string][]int{}
+	groups := make([]int, len(representatives))
+	for i, s := range representatives {
+		groups[i] = i
+		facts := make([]string, 0, len(s.Facts))
+		for fact := range s.Facts {
+			facts = append(facts, fact)
+		}
+		sort.Slice(facts, func(a, b int) bool {
+			if frequency[facts[a]] != frequency[facts[b]] {
+				return frequency[facts[a]] < frequency[facts[b]]
+			}
+			return facts[a] < facts[b]
+		})
+		prefix := len(facts) - int(math.Ceil(float64(len(facts))*minSimilarity-1e-9)) + 1
+		prefix = min(max(prefix, 1), len(facts))
+
+		compared := map[int]bool{}
+		for _, fact := range facts[:prefix] {
+			for _, j := range candidates[fact] {
+				if compared[j] {
+					continue
+				}
+				compared[j] = true
+				row := compareSchemas(representatives[j], s)
+				if row.Similarity >= minSimilarity {
+					rows = append(rows, row)
+					groups[findSchemaGroup(groups, i)] = findSchemaGroup(groups, j)
+				}
+			}
+			candidates[fact] = append(candidates[fact], i)
+		}
+	}
+
+	// Each group is named after its first schema
+	groupIds := map[int]string{}
+	for i, s := range representatives {
+		if _, ok := groupIds[findSchemaGroup(groups, i)]; !ok {
+			groupIds[findSchemaGroup(groups, i)] = s.Hash
+		}
+	}
+	index := map[*canonicalSchema]int{}
+	for i, s := range representatives {
+		index[s] = i
+	}
+	for i := range rows {
+		rows[i].GroupId = groupIds[findSchemaGroup(groups, index[first[rows[i].StructuralHash]])]
+		rows[i].MinSimilarity = minSimilarity
+	}
+	sort.SliceStable(rows, func(a, b int) bool {
+		return rows[a].GroupId < rows[b].GroupId
+	})
+	return rows
+}
+
+// findSchemaGroup returns the group of the schema at i, where groups holds
+// the index of another schema of the same group for each schema
+func findSchemaGroup(groups []int, i int) int {
+	for groups[i] != i {
+		groups[i] = groups[groups[i]]
+		i = groups[i]
+	}
+	return i
+}
+
+// canonicalSchemas returns the component schemas of the document at path,
+// with local references inlined and documentation removed, so that only the
+// structure of each schema is compared.
+func canonicalSchemas(ctx context.Context, d *plugin.QueryData, path string) ([]*canonicalSchema, error) {
+	idx, err := getDocIndex(ctx, d, path)
+	if err != nil {
+		return nil, err
+	}
+	root, err := toJSONValue(idx.Doc)
+	if err != nil {
+		return nil, err
+	}
+
+	var schemas []*canonicalSchema
+	for _, s := range idx.Schemas {
+		value, _ := resolvePointer(root, s.Pointer)
+		value = canonicalSchemaValue(inlineLocalRefs(root, value), false)
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash schema %s: %v", s.Name, err)
+		}
+		sum := sha256.Sum256(data)
+
+		facts := map[string]bool{}
+		schemaFacts(value, "", facts)
+		schemas = append(schemas, &canonicalSchema{
+			Path:  path,
+			Name:  s.Name,
+			Value: value,
+			Hash:  hex.EncodeToString(sum[:]),
+			Facts: facts,
+		})
+	}
+	return schemas, nil
+}
+
+// canonicalSchemaValue returns a copy of a schema as generic JSON values
+// without the fields that document it rather than define its structure.
+// inProperties is true for the map of properties of a schema, whose keys are
+// property names rather than fields.
+func canonicalSchemaValue(v interface{}, inProperties bool) interface{} {
+	switch c := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(c))
+		for k, item := range c {
+			if !inProperties && (strings.HasPrefix(k, "x-") || k == "description" || k == "title" || k == "example" || k == "examples" || k == "externalDocs" || k == "xml") {
+				continue
+			}
+			if k == "required" && !inProperties {
+				m[k] = sortedJSONStrings(item)
+				continue
+			}
+			m[k] = canonicalSchemaValue(item, !inProperties && k == "properties")
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(c))
+		for i, item := range c {
+			s[i] = canonicalSchemaValue(item, false)
+		}
+		return s
+	}
+	return v
+}
+
+// sortedJSONStrings sorts a list of strings, since the order of required
+// properties has no meaning
+func sortedJSONStrings(v interface{}) interface{} {
+	items, ok := v.([]interface{})
+	if !ok {
+		return v
+	}
+	s := make([]string, 0, len(items))
+	for _, item := range items {
+		if str, ok := item.(string); ok {
+			s = append(s, str)
+		}
+	}
+	sort.Strings(s)
+	sorted := make([]interface{}, len(s))
+	for i, str := range s {
+		sorted[i] = str
+	}
+	return sorted
+}
+
+// schemaFacts records every leaf value of v with its pointer
+func schemaFacts(v interface{}, ptr string, facts map[string]bool) {
+	switch c := v.(type) {
+	case map[string]interface{}:
+		if len(c) == 0 {
+			facts[ptr+"={}"] = true
+		}
+		for k, item := range c {
+			schemaFacts(item, pointerJoin(ptr, k), facts)
+		}
+	case []interface{}:
+		data, _ := json.Marshal(c)
+		facts[ptr+"="+string(data)] = true
+	default:
+		data, _ := json.Marshal(c)
+		facts[ptr+"="+string(data)] = true
+	}
+}
+
+// compareSchemas returns the similarity of two schemas, as the share of the
+// leaves of their structures they have in common
+func compareSchemas(a *canonicalSchema, b *canonicalSchema) openAPISchemaDuplicate {
+	row := openAPISchemaDuplicate{
+		Path:                a.Path,
+		Name:                a.Name,
+		OtherPath:           b.Path,
+		OtherName:           b.Name,
+		StructuralHash:      a.Hash,
+		OtherStructuralHash: b.Hash,
+		IsIdentical:         a.Hash == b.Hash,
+		DifferingProperties: []string{},
+	}
+	if row.IsIdentical {
+		row.Similarity = 1
+		return row
+	}
+
+	common := 0
+	for fact := range a.Facts {
+		if b.Facts[fact] {
+			common++
+		}
+	}
+	if total := len(a.Facts) + len(b.Facts) - common; total > 0 {
+		row.Similarity = float64(common) / float64(total)
+	}
+
+	aProperties := schemaProperties(a.Value)
+	bProperties := schemaProperties(b.Value)
+	names := map[string]bool{}
+	for name := range aProperties {
+		names[name] = true
+	}
+	for name := range bProperties {
+		names[name] = true
+	}
+	for _, name := range sortedKeys(names) {
+		av, aok := aProperties[name]
+		bv, bok := bProperties[name]
+		if !aok || !bok || !reflect.DeepEqual(av, bv) {
+			row.DifferingProperties = append(row.DifferingProperties, name)
+		}
+	}
+	return row
+}
+
+func schemaProperties(v interface{}) map[string]interface{} {
+	m, _ := v.(map[string]interface{})
+	properties, _ := m["properties"].(map[string]interface{})
+	return properties
+}
@@ -21,12 +21,12 @@ func tableOpenAPIServer(ctx context.Context) *plugin.Table {
 			Hydrate:       listOpenAPIServers,
 			KeyColumns:    plugin.OptionalColumns([]string{"path"}),
 		},
-		Columns: []*plugin.Column{
+		Columns: commonColumns([]*plugin.Column{
 			{Name: "url", Description: "A URL to the target host.", Type: proto.ColumnType_STRING, Transform: transform.FromField("URL")},
 			{Name: "description", Description: "An optional string describing the host designated by the URL.", Type: proto.ColumnType_STRING},
 			{Name: "variables", Description: "A map between a variable name and its value, used for substitution in the server's URL template.", Type: proto.ColumnType_JSON},
 			{Name: "path", Description: "Path to the file.", Type: proto.ColumnType_STRING},
-		},
+		}),
 	}
 }
 
@@ -0,0 +1,184 @@
+package openapi
+
+import (
+	"context"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+//// TABLE DEFINITION
+
+func tableOpenAPIStats(ctx context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "openapi_stats",
+		Description: "Summary statistics of each definition file.",
+		List: &plugin.ListConfig{
+			ParentHydrate: listOpenAPIFiles,
+			Hydrate:       listOpenAPIStats,
+			KeyColumns:    plugin.OptionalColumns([]string{"path"}),
+		},
+		Columns: commonColumns([]*plugin.Column{
+			{Name: "operation_count", Description: "The number of operations.", Type: proto.ColumnType_INT},
+			{Name: "operation_count_by_method", Description: "The number of operations for each HTTP method, e.g. {\"GET\": 12, \"POST\": 4}.", Type: proto.ColumnType_JSON},
+			{Name: "deprecated_operation_count", Description: "The number of deprecated operations.", Type: proto.ColumnType_INT},
+			{Name: "schema_count", Description: "The number of component schemas.", Type: proto.ColumnType_INT},
+			{Name: "parameter_count", Description: "The number of parameters declared on operations and paths, including references to component parameters.", Type: proto.ColumnType_INT},
+			{Name: "response_count", Description: "The number of responses declared on operations, including references to component responses.", Type: proto.ColumnType_INT},
+			{Name: "tag_count", Description: "The number of distinct tags, declared at the top level or used by operations.", Type: proto.ColumnType_INT},
+			{Name: "description_percent", Description: "The percentage of operations with a description.", Type: proto.ColumnType_DOUBLE},
+			{Name: "example_percent", Description: "The percentage of operations with an example for at least one parameter, request body or response.", Type: proto.ColumnType_DOUBLE},
+			{Name: "operation_id_percent", Description: "The percentage of operations with an operationId.", Type: proto.ColumnType_DOUBLE},
+			{Name: "security_percent", Description: "The percentage of operations requiring authentication, through their own security requirements or those of the document.", Type: proto.ColumnType_DOUBLE},
+			{Name: "path", Description: "Path to the file.", Type: proto.ColumnType_STRING},
+		}),
+	}
+}
+
+type openAPIStats struct {
+	Path                     string
+	OperationCount           int
+	OperationCountByMethod   map[string]int
+	DeprecatedOperationCount int
+	SchemaCount              int
+	ParameterCount           int
+	ResponseCount            int
+	TagCount                 int
+	DescriptionPercent       *float64
+	ExamplePercent           *float64
+	OperationIdPercent       *float64
+	SecurityPercent          *float64
+}
+
+//// LIST FUNCTION
+
+func listOpenAPIStats(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	// The path comes from a parent hydrate, defaulting to the config paths or
+	// available by the optional key column
+	path := h.Item.(filePath).Path
+
+	// Get the indexed contents
+	idx, err := getDocIndex(ctx, d, path)
+	if err != nil {
+		plugin.Logger(ctx).Error("openapi_stats.listOpenAPIStats", "parse_error", err)
+		return nil, err
+	}
+
+	stats := openAPIStats{
+		Path:                   path,
+		OperationCount:         len(idx.Operations),
+		OperationCountByMethod: map[string]int{},
+		SchemaCount:            len(idx.Schemas),
+		ParameterCount:         len(idx.Parameters),
+		ResponseCount:          len(idx.Responses),
+	}
+
+	tags := map[string]bool{}
+	for _, tag := range idx.Doc.Tags {
+		if tag != nil {
+			tags[tag.Name] = true
+		}
+	}
+
+	var described, withExamples, withOperationId, secured int
+	for _, op := range idx.Operations {
+		stats.OperationCountByMethod[strings.ToUpper(op.Method)]++
+		if op.Operation.Deprecated {
+			stats.DeprecatedOperationCount++
+		}
+		if op.Operation.Description != "" {
+			described++
+		}
+		if operationHasExample(op.PathItem, op.Operation) {
+			withExamples++
+		}
+		if op.Operation.OperationID != "" {
+			withOperationId++
+		}
+		security := idx.Doc.Security
+		if op.Operation.Security != nil {
+			security = *op.Operation.Security
+		}
+		if requiresAuthentication(security) {
+			secured++
+		}
+		for _, tag := range op.Operation.Tags {
+			tags[tag] = true
+		}
+	}
+	stats.TagCount = len(tags)
+
+	if n := len(idx.Operations); n > 0 {
+		percent := func(count int) *float64 {
+			p := float64(count) * 100 / float64(n)
+			return &p
+		}
+		stats.DescriptionPercent = percent(described)
+		stats.ExamplePercent = percent(withExamples)
+		stats.OperationIdPercent = percent(withOperationId)
+		stats.SecurityPercent = percent(secured)
+	}
+
+	d.StreamListItem(ctx, stats)
+
+	// Context may get cancelled due to manual cancellation or if the limit has been reached
+	if d.RowsRemaining(ctx) == 0 {
+		return nil, nil
+	}
+
+	return nil, nil
+}
+
+// requiresAuthentication returns true unless there are no security
+// requirements, or one of them is empty, which makes authentication optional
+func requiresAuthentication(security openapi3.SecurityRequirements) bool {
+	if len(security) == 0 {
+		return false
+	}
+	for _, requirement := range security {
+		if len(requirement) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// operationHasExample returns true if any parameter, the request body or any
+// response of the operation has an example
+func operationHasExample(item *openapi3.PathItem, op *openapi3.Operation) bool {
+	for _, params := range []openapi3.Parameters{item.Parameters, op.Parameters} {
+		for _, param := range params {
+			if param == nil || param.Value == nil {
+				continue
+			}
+			p := param.Value
+			if p.Example != nil || len(p.Examples) > 0 || schemaHasExample(p.Schema) || contentHasExample(p.Content) {
+				return true
+			}
+		}
+	}
+	if body := op.RequestBody; body != nil && body.Value != nil && contentHasExample(body.Value.Content) {
+		return true
+	}
+	for _, response := range op.Responses {
+		if response != nil && response.Value != nil && contentHasExample(response.Value.Content) {
+			return true
+		}
+	}
+	return false
+}
+
+func contentHasExample(content openapi3.Content) bool {
+	for _, mt := range content {
+		if mt != nil && (mt.Example != nil || len(mt.Examples) > 0 || schemaHasExample(mt.Schema)) {
+			return true
+		}
+	}
+	return false
+}
+
+func schemaHasExample(ref *openapi3.SchemaRef) bool {
+	return ref != nil && ref.Value != nil && ref.Value.Example != nil
+}
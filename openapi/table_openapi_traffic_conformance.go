@@ -0,0 +1,190 @@
+package openapi
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+//// TABLE DEFINITION
+
+func tableOpenAPITrafficConformance(ctx context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "openapi_traffic_conformance",
+		Description: "Requests and responses recorded in HAR files, validated against the operations they match.",
+		List: &plugin.ListConfig{
+			Hydrate:    listOpenAPITrafficConformance,
+			KeyColumns: plugin.OptionalColumns([]string{"har_path", "path"}),
+		},
+		Columns: commonColumns([]*plugin.Column{
+			{Name: "har_path", Description: "Path to the HAR file.", Type: proto.ColumnType_STRING},
+			{Name: "entry_index", Description: "The position of the entry in the HAR file, starting at 0.", Type: proto.ColumnType_INT},
+			{Name: "started_date_time", Description: "The time the request started.", Type: proto.ColumnType_TIMESTAMP, Transform: transform.FromField("StartedDateTime").NullIfZero()},
+			{Name: "method", Description: "The HTTP method of the request.", Type: proto.ColumnType_STRING},
+			{Name: "url", Description: "The URL of the request.", Type: proto.ColumnType_STRING},
+			{Name: "status", Description: "The status code of the response. Null if no response was received.", Type: proto.ColumnType_INT, Transform: transform.FromField("Status").NullIfZero()},
+			{Name: "matched", Description: "True if the request matches an operation of the definition.", Type: proto.ColumnType_BOOL},
+			{Name: "operation_api_path", Description: "The API path of the operation matching the request, e.g. /pets/{petId}.", Type: proto.ColumnType_STRING, Transform: transform.FromField("OperationApiPath").NullIfZero()},
+			{Name: "operation_id", Description: "The operationId of the operation matching the request.", Type: proto.ColumnType_STRING, Transform: transform.FromField("OperationId").NullIfZero()},
+			{Name: "path_params", Description: "The values of the path parameters of the operation.", Type: proto.ColumnType_JSON},
+			{Name: "request_valid", Description: "True if the request is valid for the operation. Null if the request matches no operation.", Type: proto.ColumnType_BOOL},
+			{Name: "request_errors", Description: "Each validation error of the request, with the location and name of the invalid parameter, or the JSON pointer to the invalid value within the body.", Type: proto.ColumnType_JSON},
+			{Name: "response_valid", Description: "True if the response is valid for the operation. Null if the request matches no operation or no response was received.", Type: proto.ColumnType_BOOL},
+			{Name: "response_errors", Description: "Each validation error of the response, with the JSON pointer to the invalid value within the body.", Type: proto.ColumnType_JSON},
+			{Name: "path", Description: "Path to the definition file with the matching operation. Null if the request matches no operation of any definition.", Type: proto.ColumnType_STRING, Transform: transform.FromField("Path").NullIfZero()},
+		}),
+	}
+}
+
+type openAPITrafficConformance struct {
+	Path             string
+	HarPath          string
+	EntryIndex       int
+	StartedDateTime  string
+	Method           string
+	URL              string
+	Status           int
+	Matched          bool
+	OperationApiPath string
+	OperationId      string
+	PathParams       map[string]string
+	RequestValid     *bool
+	RequestErrors    []validationError
+	ResponseValid    *bool
+	ResponseErrors   []validationError
+}
+
+// specRouter is the router for the operations of a definition
+type specRouter struct {
+	Path   string
+	Idx    *docIndex
+	Router *operationRouter
+}
+
+//// LIST FUNCTION
+
+func listOpenAPITrafficConformance(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	harPaths := []string{}
+	if d.EqualsQuals["har_path"] != nil {
+		harPaths = append(harPaths, d.EqualsQualString("har_path"))
+	} else {
+		files, err := sourceFiles(d, GetConfig(d.Connection).HarPaths)
+		if err != nil {
+			plugin.Logger(ctx).Error("openapi_traffic_conformance.listOpenAPITrafficConformance", "config_error", err)
+			return nil, err
+		}
+		harPaths = files
+	}
+
+	routers, err := specRouters(ctx, d)
+	if err != nil {
+		plugin.Logger(ctx).Error("openapi_traffic_conformance.listOpenAPITrafficConformance", "parse_error", err)
+		return nil, err
+	}
+
+	for _, harPath := range harPaths {
+		har, err := readHAR(harPath)
+		if err != nil {
+			plugin.Logger(ctx).Error("openapi_traffic_conformance.listOpenAPITrafficConformance", "har_error", err, "har_path", harPath)
+			return nil, err
+		}
+
+		for i, entry := range har.Log.Entries {
+			base := openAPITrafficConformance{
+				HarPath:         harPath,
+				EntryIndex:      i,
+				StartedDateTime: entry.StartedDateTime,
+				Method:          entry.Request.Method,
+				URL:             entry.Request.URL,
+				Status:          entry.Response.Status,
+			}
+
+			// Each request is reported once for every definition it matches,
+			// or once without a definition if it matches none
+			rows := []openAPITrafficConformance{}
+			for _, r := range routers {
+				req, err := entry.httpRequest(ctx)
+				if err != nil {
+					plugin.Logger(ctx).Error("openapi_traffic_conformance.listOpenAPITrafficConformance", "request_error", err, "har_path", harPath, "entry_index", i)
+					return nil, err
+				}
+				matches := r.Router.match(req.Method, req.URL.EscapedPath())
+				if len(matches) == 0 {
+					continue
+				}
+				rows = append(rows, conformanceRow(ctx, base, r, matches[0], entry, req))
+			}
+			if len(rows) == 0 {
+				rows = append(rows, base)
+			}
+
+			for _, row := range rows {
+				d.StreamListItem(ctx, row)
+
+				// Context may get cancelled due to manual cancellation or if the limit has been reached
+				if d.RowsRemaining(ctx) == 0 {
+					return nil, nil
+				}
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+// conformanceRow validates the request and response of entry against the
+// operation it matched
+func conformanceRow(ctx context.Context, row openAPITrafficConformance, r *specRouter, m *routeMatch, entry *harEntry, req *http.Request) openAPITrafficConformance {
+	row.Path = r.Path
+	row.Matched = true
+	row.OperationApiPath = m.Operation.ApiPath
+	row.OperationId = m.Operation.Operation.OperationID
+	row.PathParams = m.PathParams
+
+	input, err := validateRequest(ctx, r.Idx.Doc, m, req)
+	requestValid := err == nil
+	row.RequestValid = &requestValid
+	row.RequestErrors = validationErrors(err)
+
+	if entry.Response.Status == 0 {
+		return row
+	}
+	header, body, err := entry.responseBody()
+	if err == nil {
+		err = validateResponse(ctx, input, entry.Response.Status, header, body)
+	}
+	responseValid := err == nil
+	row.ResponseValid = &responseValid
+	row.ResponseErrors = validationErrors(err)
+	return row
+}
+
+// specRouters returns a router for each configured definition, or only for
+// the definition given by the path qual
+func specRouters(ctx context.Context, d *plugin.QueryData) ([]*specRouter, error) {
+	var paths []string
+	if d.EqualsQuals["path"] != nil {
+		paths = append(paths, d.EqualsQualString("path"))
+	} else {
+		files, err := openAPIFiles(ctx, d)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range files {
+			paths = append(paths, f.Path)
+		}
+	}
+
+	var routers []*specRouter
+	for _, path := range paths {
+		idx, err := getDocIndex(ctx, d, path)
+		if err != nil {
+			return nil, err
+		}
+		routers = append(routers, &specRouter{Path: path, Idx: idx, Router: newOperationRouter(idx)})
+	}
+	return routers, nil
+}
@@ -0,0 +1,183 @@
+package openapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+//// TABLE DEFINITION
+
+func tableOpenAPIValidateRequest(ctx context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "openapi_validate_request",
+		Description: "The result of validating a request against the operation it matches.",
+		List: &plugin.ListConfig{
+			Hydrate: listOpenAPIValidateRequest,
+			KeyColumns: plugin.KeyColumnSlice{
+				{Name: "path", Require: plugin.Required},
+				{Name: "method", Require: plugin.Required},
+				{Name: "api_path", Require: plugin.Required},
+				{Name: "query", Require: plugin.Optional},
+				{Name: "headers", Require: plugin.Optional},
+				{Name: "body", Require: plugin.Optional},
+				{Name: "content_type", Require: plugin.Optional},
+			},
+		},
+		Columns: commonColumns([]*plugin.Column{
+			{Name: "method", Description: "The HTTP method of the request.", Type: proto.ColumnType_STRING},
+			{Name: "api_path", Description: "The path of the request, e.g. /pets/42, which may include a query string.", Type: proto.ColumnType_STRING},
+			{Name: "query", Description: "The query parameters of the request, as an object of values or lists of values.", Type: proto.ColumnType_JSON},
+			{Name: "headers", Description: "The headers of the request, as an object of values or lists of values.", Type: proto.ColumnType_JSON},
+			{Name: "body", Description: "The body of the request.", Type: proto.ColumnType_JSON},
+			{Name: "content_type", Description: "The content type of the body, unless given in headers. Defaults to application/json.", Type: proto.ColumnType_STRING},
+			{Name: "operation_api_path", Description: "The API path of the operation matching the request, e.g. /pets/{petId}.", Type: proto.ColumnType_STRING, Transform: transform.FromField("OperationApiPath").NullIfZero()},
+			{Name: "operation_id", Description: "The operationId of the operation matching the request.", Type: proto.ColumnType_STRING, Transform: transform.FromField("OperationId").NullIfZero()},
+			{Name: "path_params", Description: "The values of the path parameters of the operation.", Type: proto.ColumnType_JSON},
+			{Name: "valid", Description: "True if the request is valid for the operation.", Type: proto.ColumnType_BOOL},
+			{Name: "error", Description: "The validation error, if the request is not valid.", Type: proto.ColumnType_STRING, Transform: transform.FromField("Error").NullIfZero()},
+			{Name: "errors", Description: "Each validation error, with the location and name of the invalid parameter, or the JSON pointer to the invalid value within the body.", Type: proto.ColumnType_JSON},
+			{Name: "path", Description: "Path to the file.", Type: proto.ColumnType_STRING},
+		}),
+	}
+}
+
+type openAPIValidateRequest struct {
+	Path             string
+	Method           string
+	ApiPath          string
+	Query            map[string]interface{}
+	Headers          map[string]interface{}
+	Body             interface{}
+	ContentType      string
+	OperationApiPath string
+	OperationId      string
+	PathParams       map[string]string
+	Valid            bool
+	Error            string
+	Errors           []validationError
+}
+
+// validationQuals are the quals describing the request, or the request of
+// the response, to validate
+type validationQuals struct {
+	Path        string
+	Method      string
+	ApiPath     string
+	Query       map[string]interface{}
+	Headers     map[string]interface{}
+	Body        interface{}
+	ContentType string
+}
+
+//// LIST FUNCTION
+
+func listOpenAPIValidateRequest(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	quals, err := getValidationQuals(d)
+	if err != nil {
+		plugin.Logger(ctx).Error("openapi_validate_request.listOpenAPIValidateRequest", "qual_error", err)
+		return nil, err
+	}
+
+	idx, err := getDocIndex(ctx, d, quals.Path)
+	if err != nil {
+		plugin.Logger(ctx).Error("openapi_validate_request.listOpenAPIValidateRequest", "parse_error", err)
+		return nil, err
+	}
+
+	row := openAPIValidateRequest{
+		Path:        quals.Path,
+		Method:      quals.Method,
+		ApiPath:     quals.ApiPath,
+		Query:       quals.Query,
+		Headers:     quals.Headers,
+		Body:        quals.Body,
+		ContentType: quals.ContentType,
+		Valid:       true,
+		Errors:      []validationError{},
+	}
+
+	matches := newOperationRouter(idx).match(quals.Method, quals.ApiPath)
+	if len(matches) == 0 {
+		row.Valid = false
+		row.Error = noOperationError(quals.Method, quals.ApiPath)
+		row.Errors = []validationError{{Location: "path", Reason: row.Error}}
+		d.StreamListItem(ctx, row)
+		return nil, nil
+	}
+	m := matches[0]
+	row.OperationApiPath = m.Operation.ApiPath
+	row.OperationId = m.Operation.Operation.OperationID
+	row.PathParams = m.PathParams
+
+	req, err := newValidationRequest(quals.Method, quals.ApiPath, quals.Query, quals.Headers, quals.Body, quals.ContentType)
+	if err != nil {
+		plugin.Logger(ctx).Error("openapi_validate_request.listOpenAPIValidateRequest", "request_error", err)
+		return nil, err
+	}
+	if _, err := validateRequest(ctx, idx.Doc, m, req); err != nil {
+		row.Valid = false
+		row.Error = err.Error()
+		row.Errors = validationErrors(err)
+	}
+	d.StreamListItem(ctx, row)
+
+	return nil, nil
+}
+
+// getValidationQuals returns the request given by the quals
+func getValidationQuals(d *plugin.QueryData) (*validationQuals, error) {
+	q := &validationQuals{
+		Path:        d.EqualsQualString("path"),
+		Method:      d.EqualsQualString("method"),
+		ApiPath:     d.EqualsQualString("api_path"),
+		ContentType: d.EqualsQualString("content_type"),
+	}
+	if q.ContentType == "" {
+		q.ContentType = "application/json"
+	}
+
+	for name, target := range map[string]*map[string]interface{}{"query": &q.Query, "headers": &q.Headers} {
+		value, err := jsonQual(d, name)
+		if err != nil {
+			return nil, err
+		}
+		if value == nil {
+			continue
+		}
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s must be a JSON object", name)
+		}
+		*target = object
+	}
+
+	body, err := jsonQual(d, "body")
+	if err != nil {
+		return nil, err
+	}
+	q.Body = body
+	return q, nil
+}
+
+// jsonQual returns the value of a JSON qual, or nil if it is not given
+func jsonQual(d *plugin.QueryData, name string) (interface{}, error) {
+	qual := d.EqualsQuals[name]
+	if qual == nil {
+		return nil, nil
+	}
+	var value interface{}
+	if err := json.Unmarshal([]byte(qual.GetJsonbValue()), &value); err != nil {
+		return nil, fmt.Errorf("%s must be valid JSON: %w", name, err)
+	}
+	return value, nil
+}
+
+func noOperationError(method string, apiPath string) string {
+	return fmt.Sprintf("no operation matches %s %s", strings.ToUpper(method), apiPath)
+}
@@ -0,0 +1,127 @@
+package openapi
+
+import (
+	"context"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+//// TABLE DEFINITION
+
+func tableOpenAPIValidateResponse(ctx context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "openapi_validate_response",
+		Description: "The result of validating a response against the operation its request matches.",
+		List: &plugin.ListConfig{
+			Hydrate: listOpenAPIValidateResponse,
+			KeyColumns: plugin.KeyColumnSlice{
+				{Name: "path", Require: plugin.Required},
+				{Name: "method", Require: plugin.Required},
+				{Name: "api_path", Require: plugin.Required},
+				{Name: "status", Require: plugin.Required},
+				{Name: "headers", Require: plugin.Optional},
+				{Name: "body", Require: plugin.Optional},
+				{Name: "content_type", Require: plugin.Optional},
+			},
+		},
+		Columns: commonColumns([]*plugin.Column{
+			{Name: "method", Description: "The HTTP method of the request.", Type: proto.ColumnType_STRING},
+			{Name: "api_path", Description: "The path of the request, e.g. /pets/42, which may include a query string.", Type: proto.ColumnType_STRING},
+			{Name: "status", Description: "The status code of the response.", Type: proto.ColumnType_INT},
+			{Name: "headers", Description: "The headers of the response, as an object of values or lists of values.", Type: proto.ColumnType_JSON},
+			{Name: "body", Description: "The body of the response.", Type: proto.ColumnType_JSON},
+			{Name: "content_type", Description: "The content type of the body, unless given in headers. Defaults to application/json.", Type: proto.ColumnType_STRING},
+			{Name: "operation_api_path", Description: "The API path of the operation matching the request, e.g. /pets/{petId}.", Type: proto.ColumnType_STRING, Transform: transform.FromField("OperationApiPath").NullIfZero()},
+			{Name: "operation_id", Description: "The operationId of the operation matching the request.", Type: proto.ColumnType_STRING, Transform: transform.FromField("OperationId").NullIfZero()},
+			{Name: "valid", Description: "True if the response is valid for the operation.", Type: proto.ColumnType_BOOL},
+			{Name: "error", Description: "The validation error, if the response is not valid.", Type: proto.ColumnType_STRING, Transform: transform.FromField("Error").NullIfZero()},
+			{Name: "errors", Description: "Each validation error, with the JSON pointer to the invalid value within the body.", Type: proto.ColumnType_JSON},
+			{Name: "path", Description: "Path to the file.", Type: proto.ColumnType_STRING},
+		}),
+	}
+}
+
+type openAPIValidateResponse struct {
+	Path             string
+	Method           string
+	ApiPath          string
+	Status           int64
+	Headers          map[string]interface{}
+	Body             interface{}
+	ContentType      string
+	OperationApiPath string
+	OperationId      string
+	Valid            bool
+	Error            string
+	Errors           []validationError
+}
+
+//// LIST FUNCTION
+
+func listOpenAPIValidateResponse(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	// The headers, body and content type quals are those of the response
+	quals, err := getValidationQuals(d)
+	if err != nil {
+		plugin.Logger(ctx).Error("openapi_validate_response.listOpenAPIValidateResponse", "qual_error", err)
+		return nil, err
+	}
+	status := d.EqualsQuals["status"].GetInt64Value()
+
+	idx, err := getDocIndex(ctx, d, quals.Path)
+	if err != nil {
+		plugin.Logger(ctx).Error("openapi_validate_response.listOpenAPIValidateResponse", "parse_error", err)
+		return nil, err
+	}
+
+	row := openAPIValidateResponse{
+		Path:        quals.Path,
+		Method:      quals.Method,
+		ApiPath:     quals.ApiPath,
+		Status:      status,
+		Headers:     quals.Headers,
+		Body:        quals.Body,
+		ContentType: quals.ContentType,
+		Valid:       true,
+		Errors:      []validationError{},
+	}
+
+	matches := newOperationRouter(idx).match(quals.Method, quals.ApiPath)
+	if len(matches) == 0 {
+		row.Valid = false
+		row.Error = noOperationError(quals.Method, quals.ApiPath)
+		row.Errors = []validationError{{Location: "path", Reason: row.Error}}
+		d.StreamListItem(ctx, row)
+		return nil, nil
+	}
+	m := matches[0]
+	row.OperationApiPath = m.Operation.ApiPath
+	row.OperationId = m.Operation.Operation.OperationID
+
+	// Only the response is validated, so the request has no headers or body
+	req, err := newValidationRequest(quals.Method, quals.ApiPath, nil, nil, nil, "")
+	if err != nil {
+		plugin.Logger(ctx).Error("openapi_validate_response.listOpenAPIValidateResponse", "request_error", err)
+		return nil, err
+	}
+	input := validationInput(idx.Doc, m, req)
+
+	header := validationHeader(quals.Headers)
+	if quals.Body != nil && header.Get("Content-Type") == "" {
+		header.Set("Content-Type", quals.ContentType)
+	}
+	body, err := encodeBody(quals.Body, header.Get("Content-Type"))
+	if err != nil {
+		plugin.Logger(ctx).Error("openapi_validate_response.listOpenAPIValidateResponse", "body_error", err)
+		return nil, err
+	}
+	if err := validateResponse(ctx, input, int(status), header, body); err != nil {
+		row.Valid = false
+		row.Error = err.Error()
+		row.Errors = validationErrors(err)
+	}
+	d.StreamListItem(ctx, row)
+
+	return nil, nil
+}
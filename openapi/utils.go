@@ -2,11 +2,15 @@ package openapi
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"net/url"
+	"path/filepath"
 
 	"github.com/getkin/kin-openapi/openapi3"
 	filehelpers "github.com/turbot/go-kit/files"
+	"github.com/turbot/steampipe-plugin-sdk/v5/connection"
 	"github.com/turbot/steampipe-plugin-sdk/v5/memoize"
 	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
 )
@@ -14,10 +18,24 @@ import (
 var OperationTypes = []string{"connect", "delete", "get", "head", "options", "patch", "post", "put", "trace"}
 
 type filePath struct {
-	Path string
+	Path   string
+	Labels map[string]string
+	// SkippedReason is why the file is not loaded, e.g. it is too large for
+	// the memory budget. Empty for files that are loaded.
+	SkippedReason string
 }
 
 func listOpenAPIFiles(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	return nil, streamOpenAPIFiles(ctx, d, false)
+}
+
+// listOpenAPIFilesWithSkipped lists the same files as listOpenAPIFiles, and
+// the files skipped with the reason they were.
+func listOpenAPIFilesWithSkipped(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	return nil, streamOpenAPIFiles(ctx, d, true)
+}
+
+func streamOpenAPIFiles(ctx context.Context, d *plugin.QueryData, withSkipped bool) error {
 	// #1 - Path via qual
 
 	// If the path was requested through qualifier then match it exactly. Globs
@@ -25,22 +43,75 @@ func listOpenAPIFiles(ctx context.Context, d *plugin.QueryData, _ *plugin.Hydrat
 	// will never match the requested value.
 	quals := d.EqualsQuals
 	if quals["path"] != nil {
-		d.StreamListItem(ctx, filePath{Path: quals["path"].GetStringValue()})
-		return nil, nil
+		path := quals["path"].GetStringValue()
+		f := filePath{Path: path, Labels: labelsForPath(GetConfig(d.Connection), path), SkippedReason: oversizeReason(ctx, d, path)}
+		if withSkipped || f.SkippedReason == "" {
+			d.StreamListItem(ctx, f)
+		}
+		return nil
+	}
+
+	files, err := allOpenAPIFiles(ctx, d)
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		if withSkipped || f.SkippedReason == "" {
+			d.StreamListItem(ctx, f)
+		}
+	}
+
+	return nil
+}
+
+// openAPIFiles returns the definitions configured for the connection, from
+// paths, inline_specs and urls in that order, without those skipped.
+func openAPIFiles(ctx context.Context, d *plugin.QueryData) ([]filePath, error) {
+	all, err := allOpenAPIFiles(ctx, d)
+	if err != nil {
+		return nil, err
 	}
+	var files []filePath
+	for _, f := range all {
+		if f.SkippedReason == "" {
+			files = append(files, f)
+		}
+	}
+	return files, nil
+}
 
+// allOpenAPIFiles returns the definitions configured for the connection,
+// including those skipped for being too large to load.
+func allOpenAPIFiles(ctx context.Context, d *plugin.QueryData) ([]filePath, error) {
 	// #2 - paths in config
 
 	// Glob paths in config
 	// Fail if no paths are specified
 	openAPIConfig := GetConfig(d.Connection)
-	if openAPIConfig.Paths == nil {
-		return nil, errors.New("paths must be configured")
+	if openAPIConfig.Paths == nil && len(openAPIConfig.PathBlocks) == 0 && len(openAPIConfig.InlineSpecs) == 0 && len(openAPIConfig.URLs) == 0 {
+		return nil, errors.New("paths, inline_specs or urls must be configured")
+	}
+
+	var files []filePath
+
+	excludes, err := excludePatterns(openAPIConfig)
+	if err != nil {
+		return nil, err
+	}
+	sniffContent := openAPIConfig.SniffContent != nil && *openAPIConfig.SniffContent
+
+	// Gather file path matches for the globs of paths and of paths blocks,
+	// except blocks labeling inline_specs or urls
+	paths := append([]string{}, openAPIConfig.Paths...)
+	for _, b := range openAPIConfig.PathBlocks {
+		if _, ok := openAPIConfig.InlineSpecs[b.Pattern]; ok || isHTTPURL(b.Pattern) {
+			continue
+		}
+		paths = append(paths, b.Pattern)
 	}
 
-	// Gather file path matches for the glob
 	var matches []string
-	paths := openAPIConfig.Paths
+	labels := map[string]map[string]string{}
 	for _, i := range paths {
 
 		// List the files in the given source directory
@@ -48,7 +119,12 @@ func listOpenAPIFiles(ctx context.Context, d *plugin.QueryData, _ *plugin.Hydrat
 		if err != nil {
 			return nil, err
 		}
-		matches = append(matches, files...)
+		for _, f := range files {
+			if _, ok := labels[f]; !ok {
+				matches = append(matches, f)
+				labels[f] = labelsForPath(openAPIConfig, f)
+			}
+		}
 	}
 
 	// Sanitize the matches to ignore the directories
@@ -58,14 +134,82 @@ func listOpenAPIFiles(ctx context.Context, d *plugin.QueryData, _ *plugin.Hydrat
 		if filehelpers.DirectoryExists(i) {
 			continue
 		}
-		d.StreamListItem(ctx, filePath{Path: i})
+
+		// Ignore files matching exclude_paths
+		if isExcludedPath(i, excludes) {
+			continue
+		}
+
+		// Skip files too large to load within the memory budget
+		if reason := oversizeReason(ctx, d, i); reason != "" {
+			files = append(files, filePath{Path: i, Labels: labels[i], SkippedReason: reason})
+			continue
+		}
+
+		// Ignore files that are not OpenAPI or Swagger definitions
+		if sniffContent && !isSpecFile(i) {
+			plugin.Logger(ctx).Debug("listOpenAPIFiles", "path", i, "status", "not a definition file")
+			continue
+		}
+
+		files = append(files, filePath{Path: i, Labels: labels[i]})
+	}
+
+	// #3 - inline specs in config
+
+	// The name of each inline document is used as its path
+	for _, name := range sortedKeys(openAPIConfig.InlineSpecs) {
+		files = append(files, filePath{Path: name, Labels: labelsForPath(openAPIConfig, name), SkippedReason: oversizeReason(ctx, d, name)})
+	}
+
+	// #4 - urls in config
+
+	// Definitions served over HTTP use their URL as the path
+	for _, u := range openAPIConfig.URLs {
+		files = append(files, filePath{Path: u, Labels: labelsForPath(openAPIConfig, u)})
 	}
 
-	return nil, nil
+	return files, nil
+}
+
+// sourceFiles returns the files matched by patterns, e.g. the har_paths in
+// the config, ignoring directories
+func sourceFiles(d *plugin.QueryData, patterns []string) ([]string, error) {
+	var files []string
+	seen := map[string]bool{}
+	for _, i := range patterns {
+		matches, err := d.GetSourceFiles(i)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range matches {
+			if seen[f] || filehelpers.DirectoryExists(f) {
+				continue
+			}
+			seen[f] = true
+			files = append(files, f)
+		}
+	}
+	return files, nil
+}
+
+// loadedDoc is a parsed document, along with the outcome of applying any
+// configured overlays to it
+type loadedDoc struct {
+	Doc            *openapi3.T
+	OverlayActions []*overlayActionResult
 }
 
 // getDoc returns the parsed contents of the specified file
 func getDoc(ctx context.Context, d *plugin.QueryData, path string) (*openapi3.T, error) {
+	loaded, err := getLoadedDoc(ctx, d, path)
+	if err != nil {
+		return nil, err
+	}
+	return loaded.Doc, nil
+}
+
+func getLoadedDoc(ctx context.Context, d *plugin.QueryData, path string) (*loadedDoc, error) {
 	// Create custom hydrate data to pass through the path. Hydrate data
 	// is normally per-column, but we can hijack it for this case to pass
 	// through the context we need.
@@ -74,7 +218,7 @@ func getDoc(ctx context.Context, d *plugin.QueryData, path string) (*openapi3.T,
 	if err != nil {
 		return nil, err
 	}
-	return i.(*openapi3.T), nil
+	return i.(*loadedDoc), nil
 }
 
 // Cached form of getDoc, using the per-connection and parallel safe
@@ -91,6 +235,14 @@ func getDocCacheKey(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateD
 	return key, nil
 }
 
+// invalidateDoc removes the cached document, and anything derived from it,
+// for the given path so that it is parsed again on next use.
+func invalidateDoc(ctx context.Context, cache *connection.ConnectionCache, path string) {
+	cache.Delete(ctx, fmt.Sprintf("getDoc-%s", path))
+	cache.Delete(ctx, fmt.Sprintf("getDocIndex-%s", path))
+	cache.Delete(ctx, fmt.Sprintf("getBundledDoc-%s", path))
+}
+
 // getDocUncached is the actual implementation of getDoc, which should
 // be run only once per path per connection. Do not call this directly, use
 // getDoc instead.
@@ -99,13 +251,175 @@ func getDocUncached(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateD
 	// but a clever pass through of context for our case.
 	path := h.Item.(string)
 
-	doc, err := openapi3.NewLoader().LoadFromFile(path)
+	config := GetConfig(d.Connection)
+	return loadDocument(ctx, d, path, config.AllowExternalRefs != nil && *config.AllowExternalRefs)
+}
+
+// loadDocument loads the document at path and applies its overlays. If
+// externalRefs is true, references to other files and URLs are resolved,
+// otherwise they fail the load. If a cache_dir is configured, documents are
+// reused from the persistent document cache while the files they were
+// loaded from are unchanged.
+func loadDocument(ctx context.Context, d *plugin.QueryData, path string, externalRefs bool) (*loadedDoc, error) {
+	loader, err := newDocLoader(ctx, d, path, externalRefs)
 	if err != nil {
-		plugin.Logger(ctx).Error("getDocUncached", "file_error", err, "path", path)
+		plugin.Logger(ctx).Error("loadDocument", "client_error", err, "path", path)
+		return nil, err
+	}
+	cache, err := getDocumentCache(d)
+	if err != nil {
+		plugin.Logger(ctx).Error("loadDocument", "cache_error", err, "path", path)
+		return nil, err
+	}
+
+	// Wait for a free worker and enough of the memory budget
+	release, err := getDocLoadLimiter(d).acquire(ctx, docSize(d, path))
+	if err != nil {
+		plugin.Logger(ctx).Error("loadDocument", "limit_error", err, "path", path)
+		return nil, fmt.Errorf("failed to load file %s: %v", path, err)
+	}
+	defer release()
+
+	overlays := overlaysForPath(GetConfig(d.Connection), path)
+	options := documentCacheOptions(externalRefs, overlays)
+
+	// Served definitions are fetched every time, so are never cached
+	var recorder *documentCacheRecorder
+	if cache != nil && !isHTTPURL(path) {
+		if loaded := cache.load(ctx, d, path, options, externalRefs); loaded != nil {
+			plugin.Logger(ctx).Debug("loadDocument", "connection_name", d.Connection.Name, "path", path, "status", "cached")
+			return loaded, nil
+		}
+		recorder = cache.recorder(path)
+		recorder.wrap(d, loader)
+	}
+
+	doc, err := loadDoc(loader, path)
+	if err != nil {
+		plugin.Logger(ctx).Error("loadDocument", "file_error", err, "path", path)
 		return nil, fmt.Errorf("failed to load file %s: %v", path, err)
 	}
 
-	plugin.Logger(ctx).Debug("getDocUncached", "connection_name", d.Connection.Name, "path", path, "status", "done")
+	loaded := &loadedDoc{Doc: doc}
+
+	// Apply overlays to the loaded document, then load the result again so
+	// that references are resolved against the overlaid content
+	var overlaid []byte
+	if len(overlays) > 0 {
+		for _, overlay := range overlays {
+			if name, err := filehelpers.Tildefy(overlay); err == nil {
+				watchDocFile(ctx, d, path, name)
+				if recorder != nil {
+					recorder.stat(name)
+				}
+			}
+		}
+		overlayLoader, err := newDocLoader(ctx, d, path, externalRefs)
+		if err != nil {
+			plugin.Logger(ctx).Error("loadDocument", "client_error", err, "path", path)
+			return nil, err
+		}
+		if recorder != nil {
+			recorder.wrap(d, overlayLoader)
+		}
+		loaded, overlaid, err = overlayDoc(path, doc, overlays, overlayLoader)
+		if err != nil {
+			plugin.Logger(ctx).Error("loadDocument", "overlay_error", err, "path", path)
+			return nil, fmt.Errorf("failed to apply overlays to file %s: %v", path, err)
+		}
+	}
+
+	if recorder != nil {
+		recorder.store(d, options, overlaid, loaded.OverlayActions)
+	}
+
+	plugin.Logger(ctx).Debug("loadDocument", "connection_name", d.Connection.Name, "path", path, "status", "done")
+
+	return loaded, nil
+}
+
+// overlayDoc applies overlays to doc, then loads the result with loader. It
+// also returns the overlaid document as JSON.
+func overlayDoc(path string, doc *openapi3.T, overlays []string, loader *openapi3.Loader) (*loadedDoc, []byte, error) {
+	root, err := toJSONValue(doc)
+	if err != nil {
+		return nil, nil, err
+	}
+	root, actions, err := applyOverlays(root, overlays)
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := json.Marshal(root)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	location := &url.URL{Path: filepath.ToSlash(path)}
+	if isHTTPURL(path) {
+		if location, err = url.Parse(path); err != nil {
+			return nil, nil, err
+		}
+	}
+	overlaid, err := loader.LoadFromDataWithPath(data, location)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &loadedDoc{Doc: overlaid, OverlayActions: actions}, data, nil
+}
+
+// loadDoc loads the document at path, which is either a URL, one of the
+// inline_specs or a file.
+func loadDoc(loader *openapi3.Loader, path string) (*openapi3.T, error) {
+	if isHTTPURL(path) {
+		u, err := url.Parse(path)
+		if err != nil {
+			return nil, err
+		}
+		return loader.LoadFromURI(u)
+	}
+	return loader.LoadFromFile(path)
+}
+
+// newDocLoader returns a loader for the document at path. The root document
+// is read from inline_specs if path is one of its names.
+func newDocLoader(ctx context.Context, d *plugin.QueryData, path string, externalRefs bool) (*openapi3.Loader, error) {
+	loader := openapi3.NewLoader()
+	loader.Context = ctx
+	loader.IsExternalRefsAllowed = externalRefs
+
+	config := GetConfig(d.Connection)
+	client, err := getHTTPClient(d)
+	if err != nil {
+		return nil, err
+	}
+
+	// Do not use openapi3.DefaultReadFromURI, it keeps the contents of every
+	// file it reads in memory for the life of the process.
+	readers := []openapi3.ReadFromURIFunc{
+		readFromHTTP(config, client),
+		openapi3.ReadFromFile,
+	}
+
+	root := filepath.ToSlash(path)
+	readRoot := func(l *openapi3.Loader, location *url.URL) ([]byte, error) {
+		if content, ok := config.InlineSpecs[path]; ok && location.Host == "" && location.Path == root {
+			return []byte(content), nil
+		}
+		return nil, openapi3.ErrURINotSupported
+	}
+	readers = append([]openapi3.ReadFromURIFunc{readRoot}, readers...)
+
+	// Local files, i.e. the document and the files it references, are
+	// watched before they are read, so that the document is invalidated when
+	// any of them changes. Inline specs and URLs are not files.
+	read := openapi3.ReadFromURIs(readers...)
+	loader.ReadFromURIFunc = func(l *openapi3.Loader, location *url.URL) ([]byte, error) {
+		if _, inline := config.InlineSpecs[location.Path]; location.Host == "" && !inline {
+			watchDocFile(ctx, d, path, filepath.FromSlash(location.Path))
+		}
+		return read(l, location)
+	}
 
-	return doc, nil
+	return loader, nil
 }